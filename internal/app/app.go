@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/connorkuljis/block-cli/internal/blocker"
@@ -12,40 +15,141 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-func Start(w io.Writer, db *sqlx.DB, currentTask tasks.Task) error {
-	blocker := blocker.NewBlocker()
+// runSession runs the interactive session and returns its elapsed seconds and
+// completion percent. Tests replace it, since it needs a terminal.
+var runSession = interactive.Run
+
+// Start runs a session for currentTask and stores how it went. Once it ends,
+// and the task is saved and the block lifted, the outcome is asked for on
+// review, unless review is nil. A planned task is taken off the plan rather
+// than stored again.
+func Start(w io.Writer, db *sqlx.DB, currentTask *tasks.Task, b blocker.Blocker, review io.Reader) (err error) {
+	var blocking bool
 	if currentTask.BlockerEnabled == 1 {
-		n, err := blocker.Start()
+		n, err := b.Start()
 		if err != nil {
 			return err
 		}
 		slog.Info(fmt.Sprintf("Blocker started (%d bytes written).", n))
+		blocking = true
+
+		// always lift the block, even when returning early with an error.
+		defer func() {
+			if !blocking {
+				return
+			}
+			if stopErr := liftBlock(b); stopErr != nil && err == nil {
+				err = stopErr
+			}
+		}()
+
+		defer stopOnSignal(b)()
 	}
 
-	err := tasks.InsertTask(db, &currentTask)
-	if err != nil {
-		return err
+	// a planned task is already stored, with its tags.
+	if currentTask.IsPlanned() {
+		err = tasks.StartPlannedTask(db, currentTask, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		currentTask.SetRunning(os.Getpid())
+		err = tasks.InsertTask(db, currentTask)
+		if err != nil {
+			return err
+		}
 	}
 
-	totalTimeSeconds, percent := interactive.Run(w, &currentTask, blocker, db)
+	if len(currentTask.Tags) > 0 {
+		err = tasks.SetTaskTags(db, currentTask.TaskId, currentTask.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	totalTimeSeconds, percent := runSession(w, currentTask, b, db)
 	finishTime := time.Now()
 
 	currentTask.SetActualDuration(totalTimeSeconds)
 	currentTask.SetCompletionPercent(percent)
+	if currentTask.IsStopwatch() {
+		// a stopwatch only ends by being stopped, so it always completes.
+		currentTask.Completed = 1
+	}
 	currentTask.SetFinishTime(finishTime)
+	currentTask.SetFinalStatus()
 
-	err = tasks.UpdateTaskAsFinished(db, currentTask)
+	err = tasks.UpdateTaskAsFinished(db, *currentTask)
 	if err != nil {
 		return err
 	}
 
-	if currentTask.BlockerEnabled == 1 {
-		n, err := blocker.Stop()
-		if err != nil {
+	// the review may be left open, or interrupted, for any length of time, so
+	// the block is lifted before asking.
+	if blocking {
+		blocking = false
+		if err := liftBlock(b); err != nil {
 			return err
 		}
-		slog.Info(fmt.Sprintf("Blocker stopped (%d bytes written).", n))
 	}
 
+	notifyIfOverBudget(db, *currentTask)
+
+	if review != nil {
+		if err := Review(review, w, currentTask); err != nil {
+			slog.Warn("Unable to record outcome.", "error", err)
+			return nil
+		}
+
+		if err := tasks.UpdateOutcome(db, *currentTask); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LeaseExpiry is how long the block for task is trusted before it counts as
+// orphaned. A stopwatch has no end to expire at, so its lease only goes stale
+// with the process.
+func LeaseExpiry(task *tasks.Task) time.Duration {
+	if task.IsStopwatch() {
+		return 0
+	}
+	return time.Duration(task.EstimatedDurationSeconds.Int64)*time.Second + blocker.LeaseGrace
+}
+
+func liftBlock(b blocker.Blocker) error {
+	n, err := b.Stop()
+	if err != nil {
+		return err
+	}
+	slog.Info(fmt.Sprintf("Blocker stopped (%d bytes written).", n))
 	return nil
 }
+
+// stopOnSignal lifts the block if the process is asked to terminate, since
+// deferred calls do not run when a signal ends the process. The returned func
+// stops listening, for when the session ends normally.
+func stopOnSignal(b blocker.Blocker) func() {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		select {
+		case s := <-sig:
+			slog.Info("Received signal, stopping blocker.", "signal", s)
+			if _, err := b.Stop(); err != nil {
+				slog.Error("Unable to stop blocker, run `sudo block recover`.", "error", err)
+			}
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestStart(t *testing.T) {
+	testCases := []struct {
+		name    string
+		elapsed int
+		percent float64
+		review  string
+		status  string
+		note    string
+		rating  int64
+	}{
+		{name: "Completed", elapsed: 1500, percent: 100, status: tasks.StatusCompleted},
+		{name: "Cancelled", elapsed: 600, percent: 40, status: tasks.StatusCancelled},
+		{name: "Reviewed", elapsed: 1500, percent: 100, review: "went well\n4\n", status: tasks.StatusCompleted, note: "went well", rating: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDb := dbtest.Open(t)
+			b, _ := blocker.NewNoopBlocker(blocker.Options{})
+
+			var blockedDuringRun bool
+			run := runSession
+			t.Cleanup(func() { runSession = run })
+			runSession = func(w io.Writer, task *tasks.Task, b blocker.Blocker, db *sqlx.DB) (int, float64) {
+				status, _ := b.Status()
+				blockedDuringRun = status.Active
+				return tc.elapsed, tc.percent
+			}
+
+			var review io.Reader
+			if tc.review != "" {
+				review = strings.NewReader(tc.review)
+			}
+
+			task := tasks.NewTask("deep work", 1500, true, false, time.Now())
+			task.Tags = []string{"api"}
+			if err := Start(io.Discard, sqlDb, task, b, review); err != nil {
+				t.Fatal(err)
+			}
+
+			if starts, stops := b.Calls(); !blockedDuringRun || starts != 1 || stops != 1 {
+				t.Errorf("Expected the block during the session, got: active %v, %d starts, %d stops", blockedDuringRun, starts, stops)
+			}
+
+			stored, err := tasks.GetTaskByID(sqlDb, task.TaskId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stored.StatusName() != tc.status || stored.ActualDurationSeconds.Int64 != int64(tc.elapsed) || !stored.FinishedAt.Valid {
+				t.Errorf("Expected: %v after %vs, got: %v after %vs", tc.status, tc.elapsed, stored.StatusName(), stored.ActualDurationSeconds.Int64)
+			}
+
+			if stored.Note.String != tc.note || stored.Rating.Int64 != tc.rating {
+				t.Errorf("Expected: %q %v, got: %q %v", tc.note, tc.rating, stored.Note.String, stored.Rating.Int64)
+			}
+
+			withTags := []tasks.Task{stored}
+			if err := tasks.AttachTags(sqlDb, withTags); err != nil {
+				t.Fatal(err)
+			}
+			if tags := withTags[0].Tags; len(tags) != 1 || tags[0] != "api" {
+				t.Errorf("Expected: %v, got: %v", task.Tags, tags)
+			}
+		})
+	}
+}
+
+func TestResumedStopwatchLease(t *testing.T) {
+	// the dns backend only writes the lease, so it runs without root.
+	home := t.TempDir()
+	config.Cfg = config.AppConfig{HiddenConfig: config.NewHiddenConfig(home), RootConfig: config.NewRootConfig(home)}
+	config.Cfg.HiddenConfig.Config.BlockGroups = map[string][]string{"social": {"reddit.com"}}
+	if err := os.MkdirAll(config.Cfg.RootConfig.Path, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	parent := tasks.NewStopwatchTask("inbox", true, false, start)
+	parent.TaskId = 1
+	parent.SetActualDuration(600)
+	parent.SetFinishTime(start.Add(10 * time.Minute))
+	parent.SetFinalStatus()
+
+	segment, err := tasks.NewSegment(*parent, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := blocker.NewDNSBlocker(blocker.Options{Groups: []string{"social"}, Expiry: LeaseExpiry(segment)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	lease, err := blocker.ReadLease(config.GetLeasePath())
+	if err != nil || lease == nil {
+		t.Fatalf("Expected a lease, got: %v, %v", lease, err)
+	}
+
+	if later := time.Now().Add(blocker.LeaseGrace + time.Hour); lease.Orphaned(later) {
+		t.Errorf("Expected the resumed stopwatch's lease to last while it runs, expires: %v", lease.ExpiresAt)
+	}
+}
+
+func TestStopOnSignalReleases(t *testing.T) {
+	b, _ := blocker.NewNoopBlocker(blocker.Options{})
+
+	// the first call starts os/signal's own loop, which never exits.
+	stopOnSignal(b)()
+	time.Sleep(10 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	// one watcher per pomodoro focus phase.
+	for range 20 {
+		stopOnSignal(b)()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := runtime.NumGoroutine(); got > before {
+		t.Errorf("Expected: %v goroutines, got: %v", before, got)
+	}
+}
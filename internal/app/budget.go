@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/buckets"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// notifyIfOverBudget sends a notification for each bucket, from the task's
+// own up through its parents, that this session took over budget.
+func notifyIfOverBudget(db *sqlx.DB, task tasks.Task) {
+	if !task.BucketId.Valid {
+		return
+	}
+
+	bucketIds, err := buckets.AncestorIds(db, task.BucketId.Int64)
+	if err != nil {
+		slog.Warn("Unable to check bucket budget.", "error", err)
+		return
+	}
+
+	added := time.Duration(task.ActualDurationSeconds.Int64) * time.Second
+	for _, bucketId := range bucketIds {
+		p, crossed, err := buckets.CrossedBudget(db, bucketId, added, time.Now())
+		if err != nil {
+			slog.Warn("Unable to check bucket budget.", "error", err)
+			return
+		}
+
+		if crossed {
+			utils.Notify(fmt.Sprintf("Bucket %s is over its %s budget (%s of %s).", p.Bucket.BucketName, p.Period.Adverb(), p.Spent, p.Target))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+// Review asks how the session went and for a focus rating. Either can be
+// skipped with enter, and neither is asked if already set, e.g. by --note.
+func Review(r io.Reader, w io.Writer, task *tasks.Task) error {
+	scanner := bufio.NewScanner(r)
+
+	note := task.Note.String
+	if !task.Note.Valid {
+		fmt.Fprint(w, "How did it go? (enter to skip): ")
+		if scanner.Scan() {
+			note = scanner.Text()
+		}
+	}
+
+	rating := int(task.Rating.Int64)
+	for !task.Rating.Valid {
+		fmt.Fprint(w, "Focus rating 1-5 (enter to skip): ")
+		if !scanner.Scan() {
+			break
+		}
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			break
+		}
+
+		n, err := strconv.Atoi(text)
+		if err == nil && n >= 1 && n <= 5 {
+			rating = n
+			break
+		}
+		fmt.Fprintln(w, "Please enter a number from 1 to 5.")
+	}
+
+	return task.SetOutcome(note, rating)
+}
+
+// IsTerminal reports whether f is attached to a terminal, so prompting it
+// won't block a script.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
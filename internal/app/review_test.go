@@ -0,0 +1,44 @@
+package app
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+func TestReview(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		preset    string
+		note      string
+		rating    int64
+		hasRating bool
+	}{
+		{name: "Both", input: "went well\n4\n", note: "went well", rating: 4, hasRating: true},
+		{name: "Skipped", input: "\n\n"},
+		{name: "Retry bad rating", input: "ok\n9\nfive\n2\n", note: "ok", rating: 2, hasRating: true},
+		{name: "End of input", input: "", note: ""},
+		{name: "Note from flag", input: "3\n", preset: "from flag", note: "from flag", rating: 3, hasRating: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &tasks.Task{}
+			task.SetOutcome(tc.preset, 0)
+
+			if err := Review(strings.NewReader(tc.input), io.Discard, task); err != nil {
+				t.Fatal(err)
+			}
+
+			if task.Note.String != tc.note || task.Note.Valid != (tc.note != "") {
+				t.Errorf("Expected note: %q, got: %q (valid %v)", tc.note, task.Note.String, task.Note.Valid)
+			}
+			if task.Rating.Int64 != tc.rating || task.Rating.Valid != tc.hasRating {
+				t.Errorf("Expected rating: %v, got: %v (valid %v)", tc.rating, task.Rating.Int64, task.Rating.Valid)
+			}
+		})
+	}
+}
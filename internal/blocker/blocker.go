@@ -1,126 +1,139 @@
 package blocker
 
 import (
-	"bufio"
-	"bytes"
+	"fmt"
 	"log/slog"
-	"os"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
 )
 
 const (
-	StopToken = '~'
+	BackendHosts = "hosts"
+	BackendDNS   = "dns"
+	BackendNoop  = "noop"
 )
 
-type Blocker struct {
-	hostsFile string
+// Blocker blocks a set of domains between calls to Start and Stop. Both
+// calls are idempotent. The returned int is the number of bytes written, if
+// the backend writes anything. Renew moves the block's expiry to ttl from
+// now, as when its session is extended.
+type Blocker interface {
+	Start() (int, error)
+	Stop() (int, error)
+	Renew(ttl time.Duration) error
+	Status() (Status, error)
 }
 
-func NewBlocker() Blocker {
-	hostsFile := "/etc/hosts"
-
-	return Blocker{
-		hostsFile: hostsFile,
-	}
+// Status describes whether a backend is currently blocking.
+type Status struct {
+	Backend string
+	Active  bool
+	Domains []string
+	Since   time.Time
 }
 
-func (b *Blocker) Start() (int, error) {
-	shouldBlock := true
-	n, err := updateBlockList(b.hostsFile, shouldBlock)
-	if err != nil {
-		return n, err
-	}
-	return n, nil
-}
+// Options configures a Blocker returned by New.
+type Options struct {
+	// Groups selects the configured block groups to apply. The default groups
+	// from config are used if empty.
+	Groups []string
 
-func (b *Blocker) Stop() (int, error) {
-	shouldBlock := false
-	n, err := updateBlockList(b.hostsFile, shouldBlock)
-	if err != nil {
-		return n, err
-	}
-	return n, nil
-}
+	// Expiry bounds how long a block started by this process is trusted
+	// before it is considered orphaned. Zero means no expiry.
+	Expiry time.Duration
 
-func addComment(line []byte) []byte {
-	// true if '#' byte exists in slice
-	isComment := bytes.IndexByte(line, '#') == 0
+	// Detached blocks outlive the current process, as with block up.
+	Detached bool
 
-	if isComment {
-		return line
-	}
-	return append([]byte("# "), line...)
+	// Strict blocks refuse block down until the session ends.
+	Strict bool
 }
 
-func stripComment(line []byte) []byte {
-	isComment := bytes.IndexByte(line, '#') == 0
-
-	if isComment {
-		return bytes.TrimSpace(line[1:])
+// New returns the Blocker backend selected in config.
+func New(opts Options) (Blocker, error) {
+	backend := config.GetBlockerBackend()
+
+	switch backend {
+	case BackendHosts, "":
+		return NewHostsBlocker(opts)
+	case BackendDNS:
+		return NewDNSBlocker(opts)
+	case BackendNoop:
+		return NewNoopBlocker(opts)
+	default:
+		return nil, fmt.Errorf("Error, unknown blocker backend %q (expected hosts, dns or noop)", backend)
 	}
-	return line
 }
 
-func updateBlockList(target string, shouldBlock bool) (int, error) {
-	// open the special hosts file, (requires root password)
-	var n int
-	file, err := os.Open(target)
-	if err != nil {
-		return n, err
+// resolveOptions returns the blocklist rules selected by opts.
+func resolveOptions(opts Options) ([]string, error) {
+	groups := opts.Groups
+	if len(groups) == 0 {
+		groups = config.GetDefaultGroups()
 	}
-	defer file.Close()
 
-	sc := bufio.NewScanner(file)
+	rules, err := ResolveGroups(config.GetBlockGroups(), groups)
+	if err != nil {
+		return nil, err
+	}
 
-	var data []byte
-	found := false
-	// iterate over the line until stop token found
-	for sc.Scan() {
-		line := sc.Bytes()
+	// rules are validated here so bad entries fail before anything is blocked.
+	if _, err := NewMatcher(rules); err != nil {
+		return nil, err
+	}
 
-		lineHasTok := bytes.IndexByte(line, StopToken) >= 0
+	return rules, nil
+}
 
-		if lineHasTok {
-			found = true
-		}
+// RecoverOrphaned lifts a block whose lease holder is gone, whichever backend
+// started it. It reports whether a recovery took place.
+func RecoverOrphaned() (bool, error) {
+	leaseFile := config.GetLeasePath()
 
-		if !found {
-			if shouldBlock {
-				line = stripComment(line)
-			} else {
-				line = addComment(line)
-			}
-		}
+	lease, err := ReadLease(leaseFile)
+	if err != nil {
+		return false, err
+	}
 
-		line = append(line, '\n')
-		data = append(data, line...)
+	if lease == nil || !lease.Orphaned(time.Now()) {
+		return false, nil
 	}
 
-	if err = sc.Err(); err != nil {
-		return n, err
+	if err := recoverLease(leaseFile, lease); err != nil {
+		return false, err
 	}
 
-	slog.Debug(string(data))
+	return true, nil
+}
 
-	n, err = overwriteFile(target, data)
-	if err != nil {
-		return n, err
+// Recover lifts the block recorded in the lease, whether or not its holder is
+// still running, and returns the released lease. It returns nil if there was
+// no lease.
+func Recover() (*Lease, error) {
+	leaseFile := config.GetLeasePath()
+
+	lease, err := ReadLease(leaseFile)
+	if err != nil || lease == nil {
+		return nil, err
+	}
+
+	if err := recoverLease(leaseFile, lease); err != nil {
+		return nil, err
 	}
 
-	return n, nil
+	slog.Info("Lifted block from lease.", "backend", lease.Backend, "pid", lease.PID, "started", lease.StartedAt)
+	return lease, nil
 }
 
-// takes a filename and overwrites it with data
-func overwriteFile(filename string, data []byte) (int, error) {
-	var n int
-	file, err := os.Create(filename)
-	if err != nil {
-		return n, err
+// recoverLease undoes the block recorded in lease and releases it.
+func recoverLease(leaseFile string, lease *Lease) error {
+	switch lease.Backend {
+	case BackendHosts, "":
+		if _, err := updateBlockList(lease.HostsFile, nil, config.GetBackupsPath()); err != nil {
+			return err
+		}
 	}
-	defer file.Close()
 
-	n, err = file.Write(data)
-	if err != nil {
-		return n, err
-	}
-	return n, nil
+	return removeLease(leaseFile)
 }
@@ -1,50 +1,77 @@
 package blocker
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
 
-func TestRemoveComment(t *testing.T) {
+func TestReplaceSection(t *testing.T) {
+	section := renderSection([]string{"example.com"})
+	managed := "# BEGIN block-cli\n# Managed by block-cli, changes inside this section are overwritten.\n0.0.0.0 example.com\n# END block-cli\n"
+
 	testCases := []struct {
 		name     string
-		input    []byte
-		expected []byte
+		input    string
+		section  []byte
+		expected string
 	}{
-		{name: "Full line comment", input: []byte("# This is a full line comment"), expected: []byte("This is a full line comment")},
-		{name: "Inline comment", input: []byte("code here # inline comment"), expected: []byte("code here # inline comment")},
-		{name: "Whitespace only", input: []byte("   "), expected: []byte("   ")},
-		{name: "No comment", input: []byte("no comment on this line"), expected: []byte("no comment on this line")},
+		{name: "Append to file", input: "127.0.0.1 localhost\n", section: section, expected: "127.0.0.1 localhost\n" + managed},
+		{name: "Append to file without trailing newline", input: "127.0.0.1 localhost", section: section, expected: "127.0.0.1 localhost\n" + managed},
+		{name: "Replace existing section", input: "127.0.0.1 localhost\n# BEGIN block-cli\n0.0.0.0 old.com\n# END block-cli\n::1 localhost\n", section: section, expected: "127.0.0.1 localhost\n" + managed + "::1 localhost\n"},
+		{name: "Remove section", input: "127.0.0.1 localhost\n" + managed + "::1 localhost\n", section: nil, expected: "127.0.0.1 localhost\n::1 localhost\n"},
+		{name: "Remove missing section", input: "127.0.0.1 localhost\n", section: nil, expected: "127.0.0.1 localhost\n"},
+		{name: "Entries above the section are kept", input: "127.0.0.1 localhost\n# 0.0.0.0 twitter.com\n~\n", section: section, expected: "127.0.0.1 localhost\n# 0.0.0.0 twitter.com\n~\n" + managed},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := stripComment(tc.input)
-			if !reflect.DeepEqual(result, tc.expected) {
-				t.Errorf("Expected: %v, got: %v", tc.expected, result)
+			result, err := replaceSection([]byte(tc.input), tc.section)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != tc.expected {
+				t.Errorf("Expected: %q, got: %q", tc.expected, result)
 			}
 		})
 	}
 }
 
-func TestPrependComment(t *testing.T) {
+func TestReplaceSectionUnterminated(t *testing.T) {
+	_, err := replaceSection([]byte("# BEGIN block-cli\n0.0.0.0 example.com\n"), nil)
+	if !errors.Is(err, ErrUnterminatedSection) {
+		t.Errorf("Expected: %v, got: %v", ErrUnterminatedSection, err)
+	}
+}
+
+func TestResolveGroups(t *testing.T) {
+	groups := map[string][]string{
+		"social": {"twitter.com", "reddit.com"},
+		"news":   {"news.ycombinator.com", "reddit.com"},
+	}
+
 	testCases := []struct {
 		name     string
-		input    []byte
-		expected []byte
+		input    []string
+		expected []string
 	}{
-		{name: "No comment", input: []byte("no comment on this line"), expected: []byte("# no comment on this line")},
-		{name: "Inline comment", input: []byte("code here # inline comment"), expected: []byte("# code here # inline comment")},
-		{name: "Whitespace only", input: []byte("   "), expected: []byte("#    ")},
-		{name: "Full line comment", input: []byte("# This is a full line comment"), expected: []byte("# This is a full line comment")},
+		{name: "Single group", input: []string{"news"}, expected: []string{"news.ycombinator.com", "reddit.com"}},
+		{name: "All groups", input: nil, expected: []string{"news.ycombinator.com", "reddit.com", "twitter.com"}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := addComment(tc.input)
+			result, err := ResolveGroups(groups, tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("Expected: %v, got: %v", tc.expected, result)
 			}
 		})
 	}
+
+	if _, err := ResolveGroups(groups, []string{"video"}); err == nil {
+		t.Errorf("Expected an error for an unknown group")
+	}
 }
@@ -0,0 +1,135 @@
+package blocker
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+// DNSBlocker blocks domains through the local DNS sinkhole. It only records
+// the active blocklist rules in the lease; the sinkhole matches them natively,
+// wildcards included, while a live lease names this backend.
+type DNSBlocker struct {
+	leaseFile string
+	domains   []string
+
+	// pid is recorded in the lease as the process holding the block. Zero
+	// marks a manual block that is never treated as orphaned.
+	pid    int
+	ttl    time.Duration
+	strict bool
+
+	// held is the lease this blocker wrote, so Stop only lifts its own block.
+	held *Lease
+}
+
+func NewDNSBlocker(opts Options) (*DNSBlocker, error) {
+	domains, err := resolveOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &DNSBlocker{
+		leaseFile: config.GetLeasePath(),
+		domains:   domains,
+		pid:       os.Getpid(),
+		ttl:       opts.Expiry,
+		strict:    opts.Strict,
+	}
+
+	if opts.Detached {
+		b.pid = 0
+	}
+
+	return b, nil
+}
+
+// Start records the blocklist for the sinkhole. It is a no-op if this blocker
+// already holds the lease, and fails with ErrBlockHeld if another live lease
+// exists.
+func (b *DNSBlocker) Start() (int, error) {
+	var n int
+
+	if len(b.domains) == 0 {
+		return n, errors.New("Error starting blocker: no domains to block, add some with `block blocklist add`")
+	}
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return n, err
+	}
+
+	now := time.Now()
+	if lease != nil && !lease.Orphaned(now) {
+		if lease.Is(b.held) {
+			return n, nil
+		}
+		return n, heldError(lease)
+	}
+
+	lease = newLease(BackendDNS, b.pid, b.domains, now, b.ttl)
+	lease.Strict = b.strict
+	if err := writeLease(b.leaseFile, *lease); err != nil {
+		return n, err
+	}
+	b.held = lease
+
+	return n, nil
+}
+
+// Stop releases the lease so the sinkhole forwards every query again. A block
+// held by another lease is left alone.
+func (b *DNSBlocker) Stop() (int, error) {
+	var n int
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return n, err
+	}
+
+	if lease != nil && !lease.Is(b.held) {
+		return n, nil
+	}
+
+	if err := removeLease(b.leaseFile); err != nil {
+		return n, err
+	}
+	b.held = nil
+	return n, nil
+}
+
+// Renew moves the expiry of this blocker's lease to ttl from now, so an
+// extended session isn't mistaken for an orphaned one.
+func (b *DNSBlocker) Renew(ttl time.Duration) error {
+	if b.ttl == 0 {
+		return nil
+	}
+	b.ttl = ttl
+
+	lease, err := renewLease(b.leaseFile, b.held, time.Now(), ttl)
+	if err != nil {
+		return err
+	}
+	b.held = lease
+
+	return nil
+}
+
+func (b *DNSBlocker) Status() (Status, error) {
+	status := Status{Backend: BackendDNS}
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return status, err
+	}
+
+	if lease != nil && lease.Backend == BackendDNS {
+		status.Active = true
+		status.Domains = lease.Domains
+		status.Since = lease.StartedAt
+	}
+
+	return status, nil
+}
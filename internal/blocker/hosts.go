@@ -0,0 +1,266 @@
+package blocker
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+const hostsPath = "/etc/hosts"
+
+// HostsBlocker blocks domains by writing a managed section to /etc/hosts.
+type HostsBlocker struct {
+	hostsFile string
+	leaseFile string
+	backupDir string
+	domains   []string
+	hosts     []string
+
+	// pid is recorded in the lease as the process holding the block. Zero
+	// marks a manual block that is never treated as orphaned.
+	pid    int
+	ttl    time.Duration
+	strict bool
+
+	// held is the lease this blocker wrote, so Stop only lifts its own block.
+	held *Lease
+}
+
+func NewHostsBlocker(opts Options) (*HostsBlocker, error) {
+	domains, err := resolveOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the hosts file can't express wildcards or exceptions, so the rules
+	// are expanded to concrete host lines up front.
+	m, err := NewMatcher(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &HostsBlocker{
+		hostsFile: hostsPath,
+		leaseFile: config.GetLeasePath(),
+		backupDir: config.GetBackupsPath(),
+		domains:   domains,
+		hosts:     m.Expand(),
+		pid:       os.Getpid(),
+		ttl:       opts.Expiry,
+		strict:    opts.Strict,
+	}
+
+	if opts.Detached {
+		b.pid = 0
+	}
+
+	return b, nil
+}
+
+// Start writes the managed blocklist section and records a lease. It is a
+// no-op if this blocker already holds the lease, and fails with ErrBlockHeld
+// if another live lease exists.
+func (b *HostsBlocker) Start() (int, error) {
+	var n int
+
+	if len(b.hosts) == 0 {
+		return n, errors.New("Error starting blocker: no domains to block, add some with `block blocklist add`")
+	}
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return n, err
+	}
+
+	now := time.Now()
+	if lease != nil && !lease.Orphaned(now) {
+		if lease.Is(b.held) {
+			return n, nil
+		}
+		return n, heldError(lease)
+	}
+
+	lease = newLease(BackendHosts, b.pid, b.domains, now, b.ttl)
+	lease.Strict = b.strict
+	lease.HostsFile = b.hostsFile
+
+	// the lease is written first so a crash mid-update can still be undone.
+	if err := writeLease(b.leaseFile, *lease); err != nil {
+		return n, err
+	}
+	b.held = lease
+
+	n, err = updateBlockList(b.hostsFile, b.hosts, b.backupDir)
+	if err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// Stop removes the managed blocklist section and releases the lease. A block
+// held by another lease is left alone, and calling Stop when nothing is
+// blocked is a no-op.
+func (b *HostsBlocker) Stop() (int, error) {
+	var n int
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return n, err
+	}
+
+	if lease != nil && !lease.Is(b.held) {
+		return n, nil
+	}
+
+	n, err = updateBlockList(b.hostsFile, nil, b.backupDir)
+	if err != nil {
+		return n, err
+	}
+
+	if err := removeLease(b.leaseFile); err != nil {
+		return n, err
+	}
+	b.held = nil
+	return n, nil
+}
+
+// Renew moves the expiry of this blocker's lease to ttl from now, so an
+// extended session isn't mistaken for an orphaned one.
+func (b *HostsBlocker) Renew(ttl time.Duration) error {
+	if b.ttl == 0 {
+		return nil
+	}
+	b.ttl = ttl
+
+	lease, err := renewLease(b.leaseFile, b.held, time.Now(), ttl)
+	if err != nil {
+		return err
+	}
+	b.held = lease
+
+	return nil
+}
+
+func (b *HostsBlocker) Status() (Status, error) {
+	status := Status{Backend: BackendHosts}
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return status, err
+	}
+
+	if lease != nil && lease.Backend == BackendHosts {
+		status.Active = true
+		status.Domains = lease.Domains
+		status.Since = lease.StartedAt
+	}
+
+	return status, nil
+}
+
+// Backups lists the hosts file backups, newest first.
+func (b *HostsBlocker) Backups() ([]Backup, error) {
+	return ListBackups(b.backupDir)
+}
+
+// RestoreBackup replaces the hosts file with the named backup, or the newest
+// backup if name is empty. The current contents are backed up first. It is
+// refused while a strict session is running, and releases the hosts lease
+// since the restored file no longer holds the block it recorded.
+func (b *HostsBlocker) RestoreBackup(name string) (Backup, int, error) {
+	var n int
+	var backup Backup
+
+	lease, err := ReadLease(b.leaseFile)
+	if err != nil {
+		return backup, n, err
+	}
+
+	if lease != nil && lease.Locked(time.Now()) {
+		return backup, n, lease.LockedError()
+	}
+
+	backups, err := b.Backups()
+	if err != nil {
+		return backup, n, err
+	}
+
+	if len(backups) == 0 {
+		return backup, n, errors.New("Error restoring hosts file: no backups found")
+	}
+
+	found := false
+	for _, candidate := range backups {
+		if name == "" || candidate.Name == name {
+			backup = candidate
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return backup, n, fmt.Errorf("Error restoring hosts file: no backup named %s", name)
+	}
+
+	contents, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return backup, n, err
+	}
+
+	n, err = overwriteFile(b.hostsFile, contents, b.backupDir)
+	if err != nil {
+		return backup, n, err
+	}
+
+	if lease != nil && (lease.Backend == BackendHosts || lease.Backend == "") {
+		if err := removeLease(b.leaseFile); err != nil {
+			return backup, n, err
+		}
+	}
+
+	return backup, n, nil
+}
+
+// updateBlockList rewrites the managed section of target to block domains,
+// removing the section entirely when domains is empty.
+func updateBlockList(target string, domains []string, backupDir string) (int, error) {
+	// read the special hosts file, (requires root password)
+	var n int
+	original, err := os.ReadFile(target)
+	if err != nil {
+		return n, err
+	}
+
+	data, err := replaceSection(original, renderSection(domains))
+	if err != nil {
+		return n, fmt.Errorf("Error updating %s: %w", target, err)
+	}
+
+	slog.Debug(string(data))
+
+	n, err = writeHostsFile(target, original, data, backupDir)
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// overwriteFile backs up filename and atomically replaces it with data.
+func overwriteFile(filename string, data []byte, backupDir string) (int, error) {
+	var n int
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		return n, err
+	}
+
+	if _, err := backupFile(filename, current, backupDir); err != nil {
+		return n, fmt.Errorf("Error backing up %s: %w", filename, err)
+	}
+
+	return atomicWriteFile(filename, data)
+}
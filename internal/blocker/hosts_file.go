@@ -0,0 +1,185 @@
+package blocker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// MaxBackups is the number of hosts file backups kept before the oldest
+	// is removed.
+	MaxBackups = 10
+
+	backupPrefix     = "hosts-"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+var ErrHostsChanged = errors.New("hosts file changed since it was read, refusing to overwrite")
+
+// Backup is a timestamped copy of the hosts file taken before a rewrite.
+type Backup struct {
+	Name      string
+	Path      string
+	CreatedAt time.Time
+}
+
+// writeHostsFile replaces target with data if its contents still match
+// expected. The previous contents are backed up to backupDir first.
+func writeHostsFile(target string, expected, data []byte, backupDir string) (int, error) {
+	var n int
+
+	current, err := os.ReadFile(target)
+	if err != nil {
+		return n, err
+	}
+
+	if !bytes.Equal(current, expected) {
+		return n, ErrHostsChanged
+	}
+
+	if bytes.Equal(current, data) {
+		return n, nil
+	}
+
+	if _, err := backupFile(target, current, backupDir); err != nil {
+		return n, fmt.Errorf("Error backing up %s: %w", target, err)
+	}
+
+	return atomicWriteFile(target, data)
+}
+
+// atomicWriteFile writes data to a temp file in the same directory as
+// filename, fsyncs it and renames it into place, keeping the original mode
+// and owner.
+func atomicWriteFile(filename string, data []byte) (int, error) {
+	var n int
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return n, err
+	}
+
+	dir := filepath.Dir(filename)
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-")
+	if err != nil {
+		return n, err
+	}
+	// no-op once the rename has succeeded.
+	defer os.Remove(temp.Name())
+
+	n, err = temp.Write(data)
+	if err != nil {
+		temp.Close()
+		return n, err
+	}
+
+	if err = temp.Sync(); err != nil {
+		temp.Close()
+		return n, err
+	}
+
+	if err = temp.Close(); err != nil {
+		return n, err
+	}
+
+	if err = os.Chmod(temp.Name(), info.Mode().Perm()); err != nil {
+		return n, err
+	}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if int(stat.Uid) != os.Geteuid() || int(stat.Gid) != os.Getegid() {
+			if err = os.Chown(temp.Name(), int(stat.Uid), int(stat.Gid)); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	if err = os.Rename(temp.Name(), filename); err != nil {
+		return n, err
+	}
+
+	return n, syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// backupFile stores contents as a timestamped backup of filename and prunes
+// old backups beyond MaxBackups.
+func backupFile(filename string, contents []byte, backupDir string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+
+	name := backupPrefix + time.Now().Format(backupTimeFormat)
+	path := filepath.Join(backupDir, name)
+
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		return "", err
+	}
+
+	backups, err := ListBackups(backupDir)
+	if err != nil {
+		return path, err
+	}
+
+	for len(backups) > MaxBackups {
+		if err := os.Remove(backups[len(backups)-1].Path); err != nil {
+			return path, err
+		}
+		backups = backups[:len(backups)-1]
+	}
+
+	return path, nil
+}
+
+// ListBackups returns the hosts file backups in backupDir, newest first.
+func ListBackups(backupDir string) ([]Backup, error) {
+	var backups []Backup
+
+	entries, err := os.ReadDir(backupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return backups, nil
+	}
+	if err != nil {
+		return backups, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) {
+			continue
+		}
+
+		createdAt, err := time.ParseInLocation(backupTimeFormat, strings.TrimPrefix(name, backupPrefix), time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			Name:      name,
+			Path:      filepath.Join(backupDir, name),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
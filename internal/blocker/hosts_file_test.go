@@ -0,0 +1,124 @@
+package blocker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteHostsFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+
+	original := []byte("127.0.0.1 localhost\n")
+	if err := os.WriteFile(target, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("127.0.0.1 localhost\n0.0.0.0 example.com\n")
+	if _, err := writeHostsFile(target, original, data, backupDir); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(result) != string(data) {
+		t.Errorf("Expected: %q, got: %q", data, result)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644 to be kept, got: %v", info.Mode().Perm())
+	}
+
+	backups, err := ListBackups(backupDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got: %d", len(backups))
+	}
+
+	backup, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(backup) != string(original) {
+		t.Errorf("Expected backup: %q, got: %q", original, backup)
+	}
+}
+
+func TestWriteHostsFileRefusesConcurrentChange(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "hosts")
+
+	if err := os.WriteFile(target, []byte("edited by someone else\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := writeHostsFile(target, []byte("what we read\n"), []byte("what we wrote\n"), filepath.Join(dir, "backups"))
+	if !errors.Is(err, ErrHostsChanged) {
+		t.Errorf("Expected: %v, got: %v", ErrHostsChanged, err)
+	}
+}
+
+func TestRestoreBackupLease(t *testing.T) {
+	dir := t.TempDir()
+	b := &HostsBlocker{
+		hostsFile: filepath.Join(dir, "hosts"),
+		leaseFile: filepath.Join(dir, "blocker-lease.json"),
+		backupDir: filepath.Join(dir, "backups"),
+	}
+
+	unblocked := []byte("127.0.0.1 localhost\n")
+	if _, err := backupFile(b.hostsFile, unblocked, b.backupDir); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := []byte("127.0.0.1 localhost\n# BEGIN block-cli\n0.0.0.0 reddit.com\n# END block-cli\n")
+	if err := os.WriteFile(b.hostsFile, blocked, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a running strict session can't be escaped by restoring a backup.
+	lease := newLease(BackendHosts, os.Getpid(), []string{"reddit.com"}, time.Now(), time.Hour)
+	lease.Strict = true
+	if err := writeLease(b.leaseFile, *lease); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := b.RestoreBackup(""); err == nil {
+		t.Errorf("Expected restoring during a strict session to fail")
+	}
+	if result, _ := os.ReadFile(b.hostsFile); string(result) != string(blocked) {
+		t.Errorf("Expected: %q, got: %q", blocked, result)
+	}
+
+	// otherwise the restored file no longer holds the block, so its lease goes.
+	lease.Strict = false
+	if err := writeLease(b.leaseFile, *lease); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := b.RestoreBackup(""); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := os.ReadFile(b.hostsFile); string(result) != string(unblocked) {
+		t.Errorf("Expected: %q, got: %q", unblocked, result)
+	}
+	if remaining, err := ReadLease(b.leaseFile); err != nil || remaining != nil {
+		t.Errorf("Expected the lease to be released, got: %v, %v", remaining, err)
+	}
+}
@@ -0,0 +1,356 @@
+package blocker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+const (
+	FormatHosts   = "hosts"
+	FormatAdblock = "adblock"
+	FormatDomains = "domains"
+)
+
+// hosts file entries that are part of a normal system setup rather than a
+// blocklist.
+var reservedHosts = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+// SkippedLine is an input line that could not be imported.
+type SkippedLine struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+// ImportResult holds the normalised, deduplicated rules parsed from a list.
+type ImportResult struct {
+	Rules      []string
+	Duplicates int
+	Skipped    []SkippedLine
+}
+
+// ImportFile parses the blocklist at path in the given format.
+func ImportFile(path, format string) (ImportResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer file.Close()
+
+	return ParseList(file, format)
+}
+
+// ParseList parses a blocklist in hosts, adblock or plain domains format.
+// Comments and blank lines are ignored; anything else that can't be turned
+// into a rule is reported in Skipped.
+func ParseList(r io.Reader, format string) (ImportResult, error) {
+	var result ImportResult
+
+	var parseLine func(line string) ([]string, string)
+	switch format {
+	case FormatHosts:
+		parseLine = parseHostsLine
+	case FormatAdblock:
+		parseLine = parseAdblockLine
+	case FormatDomains:
+		parseLine = parseDomainsLine
+	default:
+		return result, fmt.Errorf("Error, unknown blocklist format %q (expected hosts, adblock or domains)", format)
+	}
+
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	lineNumber := 0
+	for sc.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		entries, reason := parseLine(line)
+		if reason != "" {
+			result.Skipped = append(result.Skipped, SkippedLine{Line: lineNumber, Text: line, Reason: reason})
+			continue
+		}
+
+		for _, entry := range entries {
+			rule, err := ParseRule(entry)
+			if err != nil {
+				result.Skipped = append(result.Skipped, SkippedLine{Line: lineNumber, Text: line, Reason: "invalid domain " + entry})
+				continue
+			}
+
+			normalised := rule.String()
+			if seen[normalised] {
+				result.Duplicates++
+				continue
+			}
+
+			seen[normalised] = true
+			result.Rules = append(result.Rules, normalised)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// parseHostsLine reads "0.0.0.0 example.com www.example.com # comment".
+// It returns the entries on the line, or a reason the line was skipped.
+// Comment-only lines return neither.
+func parseHostsLine(line string) ([]string, string) {
+	line, _, _ = strings.Cut(line, "#")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, ""
+	}
+
+	if len(fields) < 2 {
+		return nil, "expected an address followed by hosts"
+	}
+
+	ip := net.ParseIP(fields[0])
+	if ip == nil {
+		return nil, "invalid address " + fields[0]
+	}
+
+	if !ip.IsUnspecified() && !ip.IsLoopback() {
+		return nil, "address " + fields[0] + " does not block"
+	}
+
+	var entries []string
+	for _, host := range fields[1:] {
+		if !reservedHosts[strings.ToLower(host)] {
+			entries = append(entries, host)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, "system entry"
+	}
+
+	return entries, ""
+}
+
+// parseAdblockLine reads the host rules of adblock filter lists:
+// "||example.com^" blocks a domain and its subdomains and "@@||example.com^"
+// allows them. Cosmetic, path and option rules are skipped.
+func parseAdblockLine(line string) ([]string, string) {
+	if strings.HasPrefix(line, "!") || strings.HasPrefix(line, "[") {
+		return nil, ""
+	}
+
+	prefix := ""
+	rest := line
+	if after, ok := strings.CutPrefix(rest, "@@"); ok {
+		prefix = exceptionPrefix
+		rest = after
+	}
+
+	host, ok := strings.CutPrefix(rest, "||")
+	if !ok {
+		return nil, "unsupported rule"
+	}
+
+	host, ok = strings.CutSuffix(host, "^")
+	if !ok || strings.ContainsAny(host, "^$/*|") {
+		return nil, "unsupported rule"
+	}
+
+	return []string{prefix + wildcardPrefix + host}, ""
+}
+
+// parseDomainsLine reads one blocklist entry per line, with # comments.
+func parseDomainsLine(line string) ([]string, string) {
+	line, _, _ = strings.Cut(line, "#")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, ""
+	}
+
+	if strings.ContainsAny(line, " \t") {
+		return nil, "expected one domain per line"
+	}
+
+	return []string{line}, ""
+}
+
+// MergeRules appends rules not already present in existing.
+func MergeRules(existing, rules []string) ([]string, int) {
+	seen := make(map[string]bool, len(existing))
+	for _, rule := range existing {
+		seen[rule] = true
+	}
+
+	merged := append([]string(nil), existing...)
+	added := 0
+	for _, rule := range rules {
+		if !seen[rule] {
+			seen[rule] = true
+			merged = append(merged, rule)
+			added++
+		}
+	}
+
+	return merged, added
+}
+
+// LegacyGroup is the block group the blocklist kept at the top of the hosts
+// file by earlier versions is imported into.
+const LegacyGroup = "hosts"
+
+// legacyStopToken ended that blocklist, as the first word of its line, e.g.
+// "# ~ <-- important!".
+const legacyStopToken = "~"
+
+// ImportLegacyHosts imports, once, the entries earlier versions kept above a
+// ~ line in the hosts file into LegacyGroup, and comments out any still
+// blocking there so the block is managed by block again. It returns the rules
+// added.
+func ImportLegacyHosts() (int, error) {
+	return importLegacyHosts(hostsPath, config.GetBackupsPath())
+}
+
+func importLegacyHosts(hostsFile, backupDir string) (int, error) {
+	if config.LegacyHostsImported() {
+		return 0, nil
+	}
+
+	contents, err := os.ReadFile(hostsFile)
+	if err != nil {
+		return 0, err
+	}
+
+	// with nothing to import the hosts file isn't read again either.
+	groups := config.GetBlockGroups()
+	rules, unblocked, found := parseLegacyHosts(contents)
+	if !found {
+		return 0, config.SetLegacyHostsImported(groups)
+	}
+
+	if _, err := writeHostsFile(hostsFile, contents, unblocked, backupDir); err != nil {
+		return 0, fmt.Errorf("Error lifting the blocklist above ~ in %s: %w", hostsFile, err)
+	}
+
+	updated := make(map[string][]string, len(groups)+1)
+	for name, group := range groups {
+		updated[name] = group
+	}
+
+	var added int
+	updated[LegacyGroup], added = MergeRules(updated[LegacyGroup], rules)
+
+	return added, config.SetLegacyHostsImported(updated)
+}
+
+// parseLegacyHosts returns the rules above the ~ line of a hosts file, the
+// file with those still blocking commented out, and whether there is a ~
+// line. The entries were commented out while unblocked, and only ones sent to
+// 0.0.0.0 or :: are rules, so system entries such as the machine's own
+// hostname are left alone.
+func parseLegacyHosts(contents []byte) ([]string, []byte, bool) {
+	var above bytes.Buffer
+	var unblocked []byte
+	found := false
+
+	for _, line := range bytes.SplitAfter(contents, []byte("\n")) {
+		fields := legacyFields(string(line))
+		if !found && len(fields) > 0 && fields[0] == legacyStopToken {
+			found = true
+		}
+
+		if !found && len(fields) >= 2 {
+			if ip := net.ParseIP(fields[0]); ip != nil && ip.IsUnspecified() {
+				above.WriteString(strings.Join(fields, " ") + "\n")
+				if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+					line = append([]byte("# "), line...)
+				}
+			}
+		}
+
+		unblocked = append(unblocked, line...)
+	}
+
+	if !found {
+		return nil, contents, false
+	}
+
+	result, _ := ParseList(&above, FormatHosts)
+	return result.Rules, unblocked, true
+}
+
+// legacyFields splits a line of the legacy blocklist, commented or not.
+func legacyFields(line string) []string {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+	return strings.Fields(line)
+}
+
+// SyncSources re-imports every configured blocklist source. A synced group is
+// owned by its sources: its rules are replaced by theirs, and the config file
+// is saved only if something changed.
+func SyncSources() (map[string]ImportResult, error) {
+	results := make(map[string]ImportResult)
+
+	sources := config.GetBlocklistSources()
+	if len(sources) == 0 {
+		return results, nil
+	}
+
+	synced := make(map[string][]string)
+	for _, source := range sources {
+		result, err := ImportFile(source.Path, source.Format)
+		if err != nil {
+			return results, fmt.Errorf("Error syncing blocklist %s: %w", source.Path, err)
+		}
+
+		results[source.Path] = result
+		synced[source.Group], _ = MergeRules(synced[source.Group], result.Rules)
+	}
+
+	groups := config.GetBlockGroups()
+	changed := false
+	updated := make(map[string][]string, len(groups))
+	for name, rules := range groups {
+		updated[name] = rules
+	}
+
+	for name, rules := range synced {
+		sort.Strings(rules)
+		if !slices.Equal(updated[name], rules) {
+			updated[name] = rules
+			changed = true
+		}
+	}
+
+	if !changed {
+		return results, nil
+	}
+
+	return results, config.SetBlockGroups(updated)
+}
@@ -0,0 +1,203 @@
+package blocker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+func TestParseList(t *testing.T) {
+	testCases := []struct {
+		name       string
+		format     string
+		input      string
+		rules      []string
+		duplicates int
+		skipped    int
+	}{
+		{
+			name:   "Hosts",
+			format: FormatHosts,
+			input: `# a community hosts list
+127.0.0.1 localhost
+::1 localhost ip6-loopback
+0.0.0.0 0.0.0.0
+0.0.0.0 Reddit.com www.reddit.com # inline comment
+127.0.0.1 twitter.com
+0.0.0.0 reddit.com
+192.168.1.10 nas.local
+0.0.0.0`,
+			rules:      []string{"reddit.com", "www.reddit.com", "twitter.com"},
+			duplicates: 1,
+			skipped:    5,
+		},
+		{
+			name:   "Adblock",
+			format: FormatAdblock,
+			input: `[Adblock Plus 2.0]
+! Title: example list
+||reddit.com^
+@@||old.reddit.com^
+||youtube.com^$third-party
+example.com##.banner
+/ads/*
+||reddit.com^`,
+			rules:      []string{"*.reddit.com", "!*.old.reddit.com"},
+			duplicates: 1,
+			skipped:    3,
+		},
+		{
+			name:   "Domains",
+			format: FormatDomains,
+			input: `# plain list
+reddit.com
+*.youtube.com
+!music.youtube.com # keep music
+REDDIT.com.
+two domains.com
+bad/path.com`,
+			rules:      []string{"reddit.com", "*.youtube.com", "!music.youtube.com"},
+			duplicates: 1,
+			skipped:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseList(strings.NewReader(tc.input), tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Rules, tc.rules) {
+				t.Errorf("Expected rules: %v, got: %v", tc.rules, result.Rules)
+			}
+			if result.Duplicates != tc.duplicates {
+				t.Errorf("Expected %d duplicates, got: %d", tc.duplicates, result.Duplicates)
+			}
+			if len(result.Skipped) != tc.skipped {
+				t.Errorf("Expected %d skipped, got: %+v", tc.skipped, result.Skipped)
+			}
+		})
+	}
+
+	if _, err := ParseList(strings.NewReader(""), "csv"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestParseLegacyHosts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		rules     []string
+		unblocked string
+		found     bool
+	}{
+		{
+			name:      "Unblocked",
+			input:     "# 0.0.0.0 twitter.com\n#0.0.0.0 reddit.com www.reddit.com\n~\n127.0.0.1 localhost\n",
+			rules:     []string{"twitter.com", "reddit.com", "www.reddit.com"},
+			unblocked: "# 0.0.0.0 twitter.com\n#0.0.0.0 reddit.com www.reddit.com\n~\n127.0.0.1 localhost\n",
+			found:     true,
+		},
+		{
+			name:      "Blocked",
+			input:     "0.0.0.0 twitter.com\n127.0.0.1 localhost\n# ~ end of block-cli list\n0.0.0.0 ads.example.com\n",
+			rules:     []string{"twitter.com"},
+			unblocked: "# 0.0.0.0 twitter.com\n127.0.0.1 localhost\n# ~ end of block-cli list\n0.0.0.0 ads.example.com\n",
+			found:     true,
+		},
+		{
+			name:      "System entries",
+			input:     "127.0.0.1 localhost\n127.0.1.1 workstation\n# see ~/.hosts for more\n0.0.0.0 twitter.com # twitter\n# ~ <-- important!\n",
+			rules:     []string{"twitter.com"},
+			unblocked: "127.0.0.1 localhost\n127.0.1.1 workstation\n# see ~/.hosts for more\n# 0.0.0.0 twitter.com # twitter\n# ~ <-- important!\n",
+			found:     true,
+		},
+		{
+			name:      "No stop token",
+			input:     "127.0.0.1 localhost\n0.0.0.0 twitter.com\n",
+			unblocked: "127.0.0.1 localhost\n0.0.0.0 twitter.com\n",
+			found:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, unblocked, found := parseLegacyHosts([]byte(tc.input))
+			if found != tc.found || !reflect.DeepEqual(rules, tc.rules) {
+				t.Errorf("Expected: %v %v, got: %v %v", tc.rules, tc.found, rules, found)
+			}
+			if string(unblocked) != tc.unblocked {
+				t.Errorf("Expected: %q, got: %q", tc.unblocked, unblocked)
+			}
+		})
+	}
+}
+
+// useTempConfig points the config at a temporary home directory.
+func useTempConfig(t *testing.T) {
+	t.Helper()
+
+	home := t.TempDir()
+	config.Cfg = config.AppConfig{HiddenConfig: config.NewHiddenConfig(home), RootConfig: config.NewRootConfig(home)}
+	for _, dir := range []string{config.Cfg.HiddenConfig.Path, config.Cfg.RootConfig.Path} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImportLegacyHosts(t *testing.T) {
+	useTempConfig(t)
+
+	dir := t.TempDir()
+	hostsFile := filepath.Join(dir, "hosts")
+	backupDir := filepath.Join(dir, "backups")
+
+	// upgraded while blocked: the legacy entries are still in effect.
+	blocked := "0.0.0.0 twitter.com\n0.0.0.0 reddit.com\n# ~ <-- important!\n127.0.0.1 localhost\n"
+	if err := os.WriteFile(hostsFile, []byte(blocked), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := importLegacyHosts(hostsFile, backupDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group := config.GetBlockGroups()[LegacyGroup]; added != 2 || !reflect.DeepEqual(group, []string{"twitter.com", "reddit.com"}) {
+		t.Errorf("Expected: 2 rules in %s, got: %v %v", LegacyGroup, added, group)
+	}
+
+	expected := "# 0.0.0.0 twitter.com\n# 0.0.0.0 reddit.com\n# ~ <-- important!\n127.0.0.1 localhost\n"
+	if result, _ := os.ReadFile(hostsFile); string(result) != expected {
+		t.Errorf("Expected: %q, got: %q", expected, result)
+	}
+
+	if backups, _ := ListBackups(backupDir); len(backups) != 1 {
+		t.Errorf("Expected the blocked hosts file to be backed up, got: %v backups", len(backups))
+	}
+}
+
+func TestImportLegacyHostsWithoutStopToken(t *testing.T) {
+	useTempConfig(t)
+
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsFile, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := importLegacyHosts(hostsFile, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing to import is recorded, so the hosts file isn't scanned again.
+	if !config.LegacyHostsImported() {
+		t.Errorf("Expected the import to be recorded")
+	}
+}
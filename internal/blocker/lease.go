@@ -0,0 +1,149 @@
+package blocker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/utils"
+)
+
+// LeaseGrace is added to a session's estimated duration when computing the
+// lease expiry, so slow shutdowns are not mistaken for orphaned blocks.
+const LeaseGrace = 5 * time.Minute
+
+// ErrBlockHeld is returned by Start when another process holds a live lease.
+var ErrBlockHeld = errors.New("Error, another block is already active")
+
+// Lease records an active block so it can be undone if the process holding it
+// exits without calling Stop.
+type Lease struct {
+	PID       int       `json:"pid"`
+	Backend   string    `json:"backend"`
+	HostsFile string    `json:"hostsFile,omitempty"`
+	Domains   []string  `json:"domains"` // blocklist rules, see Rule
+	StartedAt time.Time `json:"startedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Strict leases can't be lifted with block down while their holder runs.
+	Strict bool `json:"strict,omitempty"`
+}
+
+func newLease(backend string, pid int, domains []string, now time.Time, ttl time.Duration) *Lease {
+	lease := &Lease{
+		PID:       pid,
+		Backend:   backend,
+		Domains:   domains,
+		StartedAt: now,
+	}
+
+	if ttl > 0 && pid != 0 {
+		lease.ExpiresAt = now.Add(ttl)
+	}
+
+	return lease
+}
+
+// Orphaned reports whether the process holding the lease is gone or the lease
+// has expired. Leases with a zero PID are manual blocks (block up) and are
+// never orphaned.
+func (l *Lease) Orphaned(now time.Time) bool {
+	if l.PID == 0 {
+		return false
+	}
+
+	if !l.ExpiresAt.IsZero() && now.After(l.ExpiresAt) {
+		return true
+	}
+
+	return !utils.ProcessAlive(l.PID)
+}
+
+// Is reports whether l is the lease other was written as, i.e. the same
+// holder started it at the same time.
+func (l *Lease) Is(other *Lease) bool {
+	return other != nil && l.PID == other.PID && l.StartedAt.Equal(other.StartedAt)
+}
+
+// heldError explains which block is in the way of starting another.
+func heldError(lease *Lease) error {
+	holder := fmt.Sprintf("pid %d", lease.PID)
+	if lease.PID == 0 {
+		holder = "block up"
+	}
+	return fmt.Errorf("%w (%s, since %s), wait for it to end or start without the blocker", ErrBlockHeld, holder, lease.StartedAt.Format(time.Kitchen))
+}
+
+// Locked reports whether the lease belongs to a strict session that is still
+// running.
+func (l *Lease) Locked(now time.Time) bool {
+	return l.Strict && !l.Orphaned(now)
+}
+
+// LockedError explains that a strict session's block can't be lifted yet.
+func (l *Lease) LockedError() error {
+	return fmt.Errorf("Error, strict session (pid %d) started at %s is blocking until it ends", l.PID, l.StartedAt.Format(time.Kitchen))
+}
+
+// renewLease moves the expiry of held to ttl from now, if held is still the
+// lease at path, and returns the renewed lease. Manual blocks and leases
+// without an expiry are left as they are.
+func renewLease(path string, held *Lease, now time.Time, ttl time.Duration) (*Lease, error) {
+	if held == nil || held.PID == 0 || held.ExpiresAt.IsZero() {
+		return held, nil
+	}
+
+	lease, err := ReadLease(path)
+	if err != nil || lease == nil || !lease.Is(held) {
+		return held, err
+	}
+
+	lease.ExpiresAt = now.Add(ttl)
+	if err := writeLease(path, *lease); err != nil {
+		return held, err
+	}
+
+	return lease, nil
+}
+
+// ReadLease loads the lease at path. It returns nil if no lease exists.
+func ReadLease(path string) (*Lease, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lease Lease
+	if err := json.Unmarshal(contents, &lease); err != nil {
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+func writeLease(path string, lease Lease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+
+	return config.ChownToUser(path)
+}
+
+func removeLease(path string) error {
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package blocker
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLeaseOrphaned(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		lease    Lease
+		expected bool
+	}{
+		{name: "Manual block", lease: Lease{PID: 0}, expected: false},
+		{name: "Live process", lease: Lease{PID: os.Getpid()}, expected: false},
+		{name: "Live process expired", lease: Lease{PID: os.Getpid(), ExpiresAt: now.Add(-time.Minute)}, expected: true},
+		{name: "Live process not expired", lease: Lease{PID: os.Getpid(), ExpiresAt: now.Add(time.Minute)}, expected: false},
+		{name: "Dead process", lease: Lease{PID: 1 << 22}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.lease.Orphaned(now)
+			if result != tc.expected {
+				t.Errorf("Expected: %v, got: %v", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package blocker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	wildcardPrefix  = "*."
+	exceptionPrefix = "!"
+)
+
+// Rule is a single blocklist entry. Entries take one of these forms:
+//
+//	reddit.com        blocks reddit.com only
+//	*.reddit.com      blocks reddit.com and every subdomain
+//	!old.reddit.com   allows old.reddit.com even if a wildcard blocks it
+//	!*.old.reddit.com allows old.reddit.com and every subdomain
+type Rule struct {
+	Host     string
+	Wildcard bool
+	Allow    bool
+}
+
+// ParseRule parses and normalises a blocklist entry.
+func ParseRule(entry string) (Rule, error) {
+	var rule Rule
+
+	s := strings.TrimSpace(entry)
+	if rest, ok := strings.CutPrefix(s, exceptionPrefix); ok {
+		rule.Allow = true
+		s = rest
+	}
+
+	if rest, ok := strings.CutPrefix(s, wildcardPrefix); ok {
+		rule.Wildcard = true
+		s = rest
+	}
+
+	if strings.Contains(s, "/") {
+		return rule, fmt.Errorf("Error, invalid blocklist entry %q: only hosts can be matched, not paths", entry)
+	}
+
+	host, err := NormaliseDomain(s)
+	if err != nil {
+		return rule, fmt.Errorf("Error, invalid blocklist entry %q", entry)
+	}
+
+	if strings.ContainsAny(host, "*!") {
+		return rule, fmt.Errorf("Error, invalid blocklist entry %q: wildcards are only allowed as a leading '*.'", entry)
+	}
+
+	rule.Host = host
+	return rule, nil
+}
+
+func (r Rule) String() string {
+	s := r.Host
+	if r.Wildcard {
+		s = wildcardPrefix + s
+	}
+	if r.Allow {
+		s = exceptionPrefix + s
+	}
+	return s
+}
+
+// Matcher decides whether a host is blocked by a set of rules. Exceptions
+// take precedence over blocks.
+type Matcher struct {
+	exact         map[string]bool
+	wildcard      map[string]bool
+	allowExact    map[string]bool
+	allowWildcard map[string]bool
+}
+
+func NewMatcher(entries []string) (*Matcher, error) {
+	m := &Matcher{
+		exact:         make(map[string]bool),
+		wildcard:      make(map[string]bool),
+		allowExact:    make(map[string]bool),
+		allowWildcard: make(map[string]bool),
+	}
+
+	for _, entry := range entries {
+		rule, err := ParseRule(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case rule.Allow && rule.Wildcard:
+			m.allowWildcard[rule.Host] = true
+		case rule.Allow:
+			m.allowExact[rule.Host] = true
+		case rule.Wildcard:
+			m.wildcard[rule.Host] = true
+		default:
+			m.exact[rule.Host] = true
+		}
+	}
+
+	return m, nil
+}
+
+// Blocked reports whether host is blocked. host is matched case-insensitively
+// and may have a trailing dot.
+func (m *Matcher) Blocked(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if match(host, m.allowExact, m.allowWildcard) {
+		return false
+	}
+
+	return match(host, m.exact, m.wildcard)
+}
+
+// Expand returns the concrete hosts to block for backends that cannot match
+// wildcards, such as the hosts file. A wildcard expands to its host, and to
+// the subdomains of it that other rules mention, e.g. *.reddit.com with
+// !old.reddit.com gives www.old.reddit.com, each with its www subdomain.
+// Allowed hosts are left out.
+func (m *Matcher) Expand() []string {
+	seen := make(map[string]bool)
+	var hosts []string
+
+	add := func(host string) {
+		if !seen[host] && m.Blocked(host) {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	var mentioned []string
+	for _, rules := range []map[string]bool{m.exact, m.wildcard, m.allowExact, m.allowWildcard} {
+		for host := range rules {
+			mentioned = append(mentioned, host)
+		}
+	}
+
+	for host := range m.exact {
+		add(host)
+	}
+
+	for wildcard := range m.wildcard {
+		for _, host := range mentioned {
+			if host != wildcard && !strings.HasSuffix(host, "."+wildcard) {
+				continue
+			}
+			add(host)
+			if !strings.HasPrefix(host, "www.") {
+				add("www." + host)
+			}
+		}
+	}
+
+	sort.Strings(hosts)
+	return hosts
+}
+
+// match checks host against exact rules and against wildcard rules for the
+// host and each of its parent domains.
+func match(host string, exact, wildcard map[string]bool) bool {
+	if exact[host] {
+		return true
+	}
+
+	for suffix := host; suffix != ""; {
+		if wildcard[suffix] {
+			return true
+		}
+
+		_, parent, found := strings.Cut(suffix, ".")
+		if !found {
+			break
+		}
+		suffix = parent
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package blocker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected Rule
+	}{
+		{name: "Exact", input: "reddit.com", expected: Rule{Host: "reddit.com"}},
+		{name: "Wildcard", input: "*.reddit.com", expected: Rule{Host: "reddit.com", Wildcard: true}},
+		{name: "Exception", input: "!old.reddit.com", expected: Rule{Host: "old.reddit.com", Allow: true}},
+		{name: "Wildcard exception", input: "!*.old.reddit.com", expected: Rule{Host: "old.reddit.com", Wildcard: true, Allow: true}},
+		{name: "Normalised", input: "  WWW.Reddit.com. ", expected: Rule{Host: "www.reddit.com"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseRule(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected: %+v, got: %+v", tc.expected, result)
+			}
+			if again, _ := ParseRule(result.String()); !reflect.DeepEqual(again, result) {
+				t.Errorf("Expected %q to round trip, got: %+v", result.String(), again)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "old.reddit.com/r/golang", "red*it.com", "*reddit.com", "!!reddit.com"} {
+		if _, err := ParseRule(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestMatcherBlocked(t *testing.T) {
+	m, err := NewMatcher([]string{"*.reddit.com", "!old.reddit.com", "twitter.com", "*.youtube.com", "!*.music.youtube.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "reddit.com", expected: true},
+		{host: "www.reddit.com", expected: true},
+		{host: "www.old.reddit.com", expected: true},
+		{host: "old.reddit.com", expected: false},
+		{host: "OLD.reddit.com.", expected: false},
+		{host: "notreddit.com", expected: false},
+		{host: "twitter.com", expected: true},
+		{host: "api.twitter.com", expected: false},
+		{host: "m.youtube.com", expected: true},
+		{host: "music.youtube.com", expected: false},
+		{host: "www.music.youtube.com", expected: false},
+		{host: "golang.org", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			result := m.Blocked(tc.host)
+			if result != tc.expected {
+				t.Errorf("Expected: %v, got: %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestMatcherExpand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		entries  []string
+		expected []string
+	}{
+		{
+			name:     "Wildcard",
+			entries:  []string{"*.reddit.com", "!www.reddit.com", "twitter.com", "twitter.com"},
+			expected: []string{"reddit.com", "twitter.com"},
+		},
+		{
+			name:     "Subdomain exception",
+			entries:  []string{"*.reddit.com", "!old.reddit.com"},
+			expected: []string{"reddit.com", "www.old.reddit.com", "www.reddit.com"},
+		},
+		{
+			name:     "Wildcard exception",
+			entries:  []string{"*.reddit.com", "!*.old.reddit.com"},
+			expected: []string{"reddit.com", "www.reddit.com"},
+		},
+		{
+			name:     "Explicit subdomain",
+			entries:  []string{"*.reddit.com", "np.reddit.com", "*.news.reddit.com"},
+			expected: []string{"news.reddit.com", "np.reddit.com", "reddit.com", "www.news.reddit.com", "www.np.reddit.com", "www.reddit.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMatcher(tc.entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := m.Expand()
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected: %v, got: %v", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package blocker
+
+import (
+	"sync"
+	"time"
+)
+
+// NoopBlocker blocks nothing. It counts calls to Start and Stop so sessions
+// can be exercised in tests without root privileges.
+type NoopBlocker struct {
+	mu     sync.Mutex
+	active bool
+	since  time.Time
+	starts int
+	stops  int
+}
+
+func NewNoopBlocker(opts Options) (*NoopBlocker, error) {
+	return &NoopBlocker{}, nil
+}
+
+func (b *NoopBlocker) Start() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.starts++
+	if !b.active {
+		b.active = true
+		b.since = time.Now()
+	}
+	return 0, nil
+}
+
+func (b *NoopBlocker) Stop() (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stops++
+	b.active = false
+	return 0, nil
+}
+
+func (b *NoopBlocker) Renew(ttl time.Duration) error {
+	return nil
+}
+
+func (b *NoopBlocker) Status() (Status, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{Backend: BackendNoop, Active: b.active}
+	if b.active {
+		status.Since = b.since
+	}
+	return status, nil
+}
+
+// Calls returns how many times Start and Stop have been called.
+func (b *NoopBlocker) Calls() (starts, stops int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.starts, b.stops
+}
@@ -0,0 +1,120 @@
+package blocker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	SectionBegin = "# BEGIN block-cli"
+	SectionEnd   = "# END block-cli"
+
+	sectionNotice = "# Managed by block-cli, changes inside this section are overwritten."
+	sinkholeAddr  = "0.0.0.0"
+)
+
+var ErrUnterminatedSection = errors.New("found '" + SectionBegin + "' without a matching '" + SectionEnd + "'")
+
+// ResolveGroups returns the sorted, deduplicated domains of the named groups.
+// If names is empty every group is used.
+func ResolveGroups(groups map[string][]string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		for name := range groups {
+			names = append(names, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var domains []string
+	for _, name := range names {
+		group, ok := groups[name]
+		if !ok {
+			return nil, fmt.Errorf("Error resolving block groups: unknown group %s", name)
+		}
+
+		for _, domain := range group {
+			if !seen[domain] {
+				seen[domain] = true
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// NormaliseDomain lower-cases a domain and strips surrounding whitespace and
+// any trailing dot.
+func NormaliseDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+
+	if domain == "" || strings.ContainsAny(domain, " \t#/") {
+		return domain, fmt.Errorf("Error, invalid domain: %q", domain)
+	}
+
+	return domain, nil
+}
+
+// renderSection returns the managed hosts file section for domains, or nil
+// if there is nothing to block.
+func renderSection(domains []string) []byte {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(SectionBegin + "\n")
+	buf.WriteString(sectionNotice + "\n")
+	for _, domain := range domains {
+		buf.WriteString(sinkholeAddr + " " + domain + "\n")
+	}
+	buf.WriteString(SectionEnd + "\n")
+
+	return buf.Bytes()
+}
+
+// replaceSection swaps the managed section in contents for section. The
+// section is appended if absent, and removed if section is nil. Lines outside
+// the section are left untouched.
+func replaceSection(contents []byte, section []byte) ([]byte, error) {
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+
+	begin, end := -1, -1
+	for i, line := range lines {
+		trimmed := string(bytes.TrimSpace(line))
+		if trimmed == SectionBegin && begin == -1 {
+			begin = i
+		} else if trimmed == SectionEnd && begin != -1 {
+			end = i
+			break
+		}
+	}
+
+	if begin != -1 && end == -1 {
+		return nil, ErrUnterminatedSection
+	}
+
+	var out bytes.Buffer
+	if begin == -1 {
+		out.Write(contents)
+		if len(section) > 0 && len(contents) > 0 && !bytes.HasSuffix(contents, []byte("\n")) {
+			out.WriteByte('\n')
+		}
+		out.Write(section)
+		return out.Bytes(), nil
+	}
+
+	for _, line := range lines[:begin] {
+		out.Write(line)
+	}
+	out.Write(section)
+	for _, line := range lines[end+1:] {
+		out.Write(line)
+	}
+
+	return out.Bytes(), nil
+}
@@ -1,21 +1,38 @@
 package buckets
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/connorkuljis/block-cli/internal/tasks"
 	"github.com/jmoiron/sqlx"
 )
 
-var BucketsSchema = `
-	CREATE TABLE IF NOT EXISTS Buckets (
-		bucket_id INTEGER PRIMARY KEY AUTOINCREMENT,
-		bucket_name string
-	);
-`
-
 type Bucket struct {
-	BucketId   int64  `db:"bucket_id"`
-	BucketName string `db:"bucket_name"`
-	Tasks      []tasks.Task
+	BucketId   int64         `db:"bucket_id"`
+	BucketName string        `db:"bucket_name"`
+	ArchivedAt sql.NullTime  `db:"archived_at"`
+	ParentId   sql.NullInt64 `db:"parent_id"`
+
+	BudgetSeconds sql.NullInt64  `db:"budget_seconds"`
+	BudgetPeriod  sql.NullString `db:"budget_period"`
+
+	Tasks []tasks.Task
+}
+
+func (b Bucket) Archived() bool {
+	return b.ArchivedAt.Valid
+}
+
+// Summary is a bucket with totals over its tasks.
+type Summary struct {
+	Bucket
+	TaskCount    int64 `db:"task_count"`
+	TotalSeconds int64 `db:"total_seconds"`
 }
 
 func GetAllBuckets(db *sqlx.DB) ([]Bucket, error) {
@@ -30,6 +47,46 @@ func GetAllBuckets(db *sqlx.DB) ([]Bucket, error) {
 	return buckets, nil
 }
 
+// GetSummaries totals the tasks in each bucket, leaving out archived
+// buckets unless asked for.
+func GetSummaries(db *sqlx.DB, includeArchived bool) ([]Summary, error) {
+	var summaries []Summary
+	q := `SELECT b.*
+	, COUNT(t.task_id) AS task_count
+	, COALESCE(SUM(t.actual_duration_seconds), 0) AS total_seconds
+	FROM Buckets b
+	LEFT JOIN Tasks t ON t.bucket_id = b.bucket_id AND t.status IS NOT 'planned'
+	WHERE ? OR b.archived_at IS NULL
+	GROUP BY b.bucket_id
+	ORDER BY b.archived_at IS NOT NULL, b.bucket_name`
+
+	err := db.Select(&summaries, q, includeArchived)
+	if err != nil {
+		return summaries, err
+	}
+
+	return summaries, nil
+}
+
+func GetBucketByID(db *sqlx.DB, bucketId int64) (Bucket, error) {
+	var bucket Bucket
+	q := `SELECT * FROM Buckets WHERE bucket_id = ?`
+
+	err := db.Get(&bucket, q, bucketId)
+	if err != nil {
+		return bucket, err
+	}
+
+	tasks, err := tasks.GetTasksByBucketId(db, bucket.BucketId)
+	if err != nil {
+		return bucket, err
+	}
+
+	bucket.Tasks = tasks
+
+	return bucket, nil
+}
+
 func GetBucketByName(db *sqlx.DB, bucketName string) (Bucket, error) {
 	var bucket Bucket
 	q := `SELECT * FROM Buckets WHERE bucket_name = ?`
@@ -48,3 +105,133 @@ func GetBucketByName(db *sqlx.DB, bucketName string) (Bucket, error) {
 
 	return bucket, nil
 }
+
+// Resolve finds a bucket by name, or by id for a number that isn't also a
+// bucket name.
+func Resolve(db *sqlx.DB, nameOrId string) (Bucket, error) {
+	bucket, err := GetBucketByName(db, nameOrId)
+	if err == nil {
+		return bucket, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return bucket, err
+	}
+
+	if bucketId, convErr := strconv.ParseInt(nameOrId, 10, 64); convErr == nil {
+		bucket, err = GetBucketByID(db, bucketId)
+		if err == nil {
+			return bucket, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return bucket, err
+		}
+	}
+
+	return bucket, fmt.Errorf("Error, no bucket named %s", nameOrId)
+}
+
+// ResolveActive is Resolve for tagging new tasks, which refuses archived
+// buckets.
+func ResolveActive(db *sqlx.DB, nameOrId string) (Bucket, error) {
+	bucket, err := Resolve(db, nameOrId)
+	if err != nil {
+		return bucket, err
+	}
+
+	if bucket.Archived() {
+		return bucket, fmt.Errorf("Error, bucket %s is archived", bucket.BucketName)
+	}
+
+	return bucket, nil
+}
+
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return name, errors.New("Error, bucket name can't be empty")
+	}
+	return name, nil
+}
+
+func CreateBucket(db *sqlx.DB, name string) (Bucket, error) {
+	bucket := Bucket{}
+
+	name, err := validName(name)
+	if err != nil {
+		return bucket, err
+	}
+
+	result, err := db.Exec("INSERT INTO Buckets (bucket_name) VALUES (?)", name)
+	if err != nil {
+		return bucket, fmt.Errorf("Error creating bucket %s: %w", name, err)
+	}
+
+	bucket.BucketId, err = result.LastInsertId()
+	if err != nil {
+		return bucket, err
+	}
+	bucket.BucketName = name
+
+	return bucket, nil
+}
+
+func RenameBucket(db *sqlx.DB, bucketId int64, name string) error {
+	name, err := validName(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE Buckets SET bucket_name = ? WHERE bucket_id = ?", name, bucketId)
+	if err != nil {
+		return fmt.Errorf("Error renaming bucket %d: %w", bucketId, err)
+	}
+
+	return nil
+}
+
+// SetArchived archives a bucket, hiding it from lists and new tasks while
+// keeping its history, or restores it.
+func SetArchived(db *sqlx.DB, bucketId int64, archived bool) error {
+	archivedAt := sql.NullTime{Time: time.Now(), Valid: archived}
+
+	_, err := db.Exec("UPDATE Buckets SET archived_at = ? WHERE bucket_id = ?", archivedAt, bucketId)
+	if err != nil {
+		return fmt.Errorf("Error archiving bucket %d: %w", bucketId, err)
+	}
+
+	return nil
+}
+
+// DeleteBucket removes an empty bucket. With force, its tasks are untagged
+// first instead. Child buckets move up to the deleted bucket's parent.
+func DeleteBucket(db *sqlx.DB, bucketId int64, force bool) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.Get(&count, "SELECT COUNT(*) FROM Tasks WHERE bucket_id = ?", bucketId); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		if !force {
+			return fmt.Errorf("Error, bucket %d has %d tasks, archive it or delete with --force to untag them", bucketId, count)
+		}
+		if _, err := tx.Exec("UPDATE Tasks SET bucket_id = NULL WHERE bucket_id = ?", bucketId); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE Buckets SET parent_id = (SELECT parent_id FROM Buckets WHERE bucket_id = ?) WHERE parent_id = ?", bucketId, bucketId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM Buckets WHERE bucket_id = ?", bucketId); err != nil {
+		return fmt.Errorf("Error deleting bucket %d: %w", bucketId, err)
+	}
+
+	return tx.Commit()
+}
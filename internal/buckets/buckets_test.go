@@ -0,0 +1,77 @@
+package buckets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+func TestResolve(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	infra, _ := CreateBucket(sqlDb, "infra")
+	// a bucket named like another bucket's id wins over the id.
+	named, _ := CreateBucket(sqlDb, "1")
+	archived, _ := CreateBucket(sqlDb, "old")
+	SetArchived(sqlDb, archived.BucketId, true)
+
+	testCases := []struct {
+		input   string
+		active  bool
+		want    int64
+		wantErr bool
+	}{
+		{input: "infra", want: infra.BucketId},
+		{input: "1", want: named.BucketId},
+		{input: "3", want: archived.BucketId},
+		{input: "old", active: true, wantErr: true},
+		{input: "missing", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			resolve := Resolve
+			if tc.active {
+				resolve = ResolveActive
+			}
+
+			bucket, err := resolve(sqlDb, tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && bucket.BucketId != tc.want {
+				t.Errorf("Expected: %v, got: %v", tc.want, bucket.BucketId)
+			}
+		})
+	}
+}
+
+func TestDeleteBucket(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	bucket, _ := CreateBucket(sqlDb, "infra")
+
+	task := tasks.NewTask("k8s", 60, false, false, time.Now())
+	task.AddBucketTag(bucket.BucketId)
+	if err := tasks.InsertTask(sqlDb, task); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteBucket(sqlDb, bucket.BucketId, false); err == nil {
+		t.Errorf("Expected deleting a bucket with tasks to fail")
+	}
+
+	if err := DeleteBucket(sqlDb, bucket.BucketId, true); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := tasks.GetTaskByID(sqlDb, task.TaskId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.BucketId.Valid {
+		t.Errorf("Expected the task to be untagged, got bucket: %v", kept.BucketId.Int64)
+	}
+}
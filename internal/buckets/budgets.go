@@ -0,0 +1,180 @@
+package buckets
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Period is how often a bucket's budget resets.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+// ParsePeriod reads day, week or month, or daily, weekly or monthly.
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "day", "daily":
+		return PeriodDay, nil
+	case "week", "weekly":
+		return PeriodWeek, nil
+	case "month", "monthly":
+		return PeriodMonth, nil
+	}
+	return "", fmt.Errorf("Error, invalid period %q (expected day, week or month)", s)
+}
+
+// Adverb names the period for messages, e.g. weekly.
+func (p Period) Adverb() string {
+	if p == PeriodDay {
+		return "daily"
+	}
+	return string(p) + "ly"
+}
+
+// Start returns when the period containing now began. Weeks start on Monday.
+func (p Period) Start(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch p {
+	case PeriodWeek:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	case PeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+func (b Bucket) HasBudget() bool {
+	return b.BudgetSeconds.Valid && b.BudgetPeriod.Valid
+}
+
+// SetBudget gives a bucket a target time per period.
+func SetBudget(db *sqlx.DB, bucketId int64, target time.Duration, period Period) error {
+	if target <= 0 {
+		return fmt.Errorf("Error, budget must be positive")
+	}
+
+	_, err := db.Exec("UPDATE Buckets SET budget_seconds = ?, budget_period = ? WHERE bucket_id = ?", int64(target.Seconds()), string(period), bucketId)
+	if err != nil {
+		return fmt.Errorf("Error setting budget of bucket %d: %w", bucketId, err)
+	}
+
+	return nil
+}
+
+func ClearBudget(db *sqlx.DB, bucketId int64) error {
+	_, err := db.Exec("UPDATE Buckets SET budget_seconds = NULL, budget_period = NULL WHERE bucket_id = ?", bucketId)
+	if err != nil {
+		return fmt.Errorf("Error clearing budget of bucket %d: %w", bucketId, err)
+	}
+
+	return nil
+}
+
+// Progress is the time spent in a bucket during the current budget period.
+type Progress struct {
+	Bucket Bucket
+	Period Period
+	Since  time.Time
+	Spent  time.Duration
+	Target time.Duration
+}
+
+// Percent is the share of the target spent, which may pass 100.
+func (p Progress) Percent() float64 {
+	if p.Target <= 0 {
+		return 0
+	}
+	return float64(p.Spent) / float64(p.Target) * 100
+}
+
+func (p Progress) Over() bool {
+	return p.Spent > p.Target
+}
+
+// SpentSeconds and TargetSeconds suit the web templates.
+func (p Progress) SpentSeconds() int64 {
+	return int64(p.Spent.Seconds())
+}
+
+func (p Progress) TargetSeconds() int64 {
+	return int64(p.Target.Seconds())
+}
+
+// GetProgress adds up actual_duration_seconds of the tasks created in the
+// current period in the bucket and its children. The bucket must have a budget.
+func GetProgress(db *sqlx.DB, bucket Bucket, now time.Time) (Progress, error) {
+	if !bucket.HasBudget() {
+		return Progress{}, fmt.Errorf("Error, bucket %s has no budget", bucket.BucketName)
+	}
+
+	period := Period(bucket.BudgetPeriod.String)
+	p := Progress{
+		Bucket: bucket,
+		Period: period,
+		Since:  period.Start(now),
+		Target: time.Duration(bucket.BudgetSeconds.Int64) * time.Second,
+	}
+
+	var spent sql.NullInt64
+	q := `WITH RECURSIVE subtree(bucket_id) AS (
+		SELECT ?
+		UNION
+		SELECT b.bucket_id FROM Buckets b JOIN subtree s ON b.parent_id = s.bucket_id
+	)
+	SELECT SUM(actual_duration_seconds) FROM Tasks WHERE bucket_id IN subtree AND created_at >= ?`
+	if err := db.Get(&spent, q, bucket.BucketId, p.Since); err != nil {
+		return p, err
+	}
+	p.Spent = time.Duration(spent.Int64) * time.Second
+
+	return p, nil
+}
+
+// GetAllProgress returns the progress of every active bucket with a budget,
+// keyed by bucket id.
+func GetAllProgress(db *sqlx.DB, now time.Time) (map[int64]Progress, error) {
+	progress := make(map[int64]Progress)
+
+	var budgeted []Bucket
+	q := "SELECT * FROM Buckets WHERE budget_seconds IS NOT NULL AND budget_period IS NOT NULL AND archived_at IS NULL ORDER BY bucket_name"
+	if err := db.Select(&budgeted, q); err != nil {
+		return progress, err
+	}
+
+	for _, bucket := range budgeted {
+		p, err := GetProgress(db, bucket, now)
+		if err != nil {
+			return progress, err
+		}
+		progress[bucket.BucketId] = p
+	}
+
+	return progress, nil
+}
+
+// CrossedBudget reports whether adding the last session's time took the
+// bucket over its budget for the period, so it is only reported once.
+func CrossedBudget(db *sqlx.DB, bucketId int64, added time.Duration, now time.Time) (Progress, bool, error) {
+	bucket, err := GetBucketByID(db, bucketId)
+	if err != nil || !bucket.HasBudget() {
+		return Progress{}, false, err
+	}
+
+	p, err := GetProgress(db, bucket, now)
+	if err != nil {
+		return p, false, err
+	}
+
+	return p, p.Over() && p.Spent-added <= p.Target, nil
+}
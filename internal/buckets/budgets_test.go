@@ -0,0 +1,71 @@
+package buckets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// a Wednesday afternoon.
+	now := time.Date(2024, time.May, 15, 14, 30, 0, 0, time.Local)
+
+	testCases := []struct {
+		period Period
+		want   time.Time
+	}{
+		{period: PeriodDay, want: time.Date(2024, time.May, 15, 0, 0, 0, 0, time.Local)},
+		{period: PeriodWeek, want: time.Date(2024, time.May, 13, 0, 0, 0, 0, time.Local)},
+		{period: PeriodMonth, want: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.period), func(t *testing.T) {
+			got := tc.period.Start(now)
+			if !got.Equal(tc.want) {
+				t.Errorf("Expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+
+	// sunday belongs to the week that started the monday before.
+	sunday := time.Date(2024, time.May, 19, 9, 0, 0, 0, time.Local)
+	if got, want := PeriodWeek.Start(sunday), time.Date(2024, time.May, 13, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("Expected: %v, got: %v", want, got)
+	}
+}
+
+func TestCrossedBudget(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+	now := time.Now()
+
+	bucket, _ := CreateBucket(sqlDb, "infra")
+	if err := SetBudget(sqlDb, bucket.BucketId, time.Hour, PeriodDay); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		seconds int64
+		crossed bool
+	}{
+		{name: "under", seconds: 40 * 60, crossed: false},
+		{name: "crosses", seconds: 30 * 60, crossed: true},
+		{name: "already over", seconds: 10 * 60, crossed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addFinishedTask(t, sqlDb, bucket.BucketId, int(tc.seconds))
+
+			_, crossed, err := CrossedBudget(sqlDb, bucket.BucketId, time.Duration(tc.seconds)*time.Second, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if crossed != tc.crossed {
+				t.Errorf("Expected: %v, got: %v", tc.crossed, crossed)
+			}
+		})
+	}
+}
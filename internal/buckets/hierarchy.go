@@ -0,0 +1,133 @@
+package buckets
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Node is a bucket in the tree, with totals over its own tasks (from
+// Summary) and over its whole subtree.
+type Node struct {
+	Summary
+	Depth    int
+	Children []*Node
+
+	TreeTaskCount int64
+	TreeSeconds   int64
+}
+
+// DescendantIds returns the bucket and every bucket below it.
+func DescendantIds(db *sqlx.DB, bucketId int64) ([]int64, error) {
+	var ids []int64
+	q := `WITH RECURSIVE subtree(bucket_id) AS (
+		SELECT ?
+		UNION
+		SELECT b.bucket_id FROM Buckets b JOIN subtree s ON b.parent_id = s.bucket_id
+	)
+	SELECT bucket_id FROM subtree`
+
+	err := db.Select(&ids, q, bucketId)
+	if err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+// AncestorIds returns the bucket and every bucket above it, nearest first.
+func AncestorIds(db *sqlx.DB, bucketId int64) ([]int64, error) {
+	var ids []int64
+	q := `WITH RECURSIVE ancestors(bucket_id, depth) AS (
+		SELECT ?, 0
+		UNION
+		SELECT b.parent_id, a.depth + 1 FROM Buckets b JOIN ancestors a ON b.bucket_id = a.bucket_id
+		WHERE b.parent_id IS NOT NULL
+	)
+	SELECT bucket_id FROM ancestors ORDER BY depth`
+
+	err := db.Select(&ids, q, bucketId)
+	if err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+// SetParent moves a bucket under parentId, or to the top level for 0. A
+// bucket can't be moved under itself or one of its own children.
+func SetParent(db *sqlx.DB, bucketId int64, parentId int64) error {
+	parent := sql.NullInt64{Int64: parentId, Valid: parentId != 0}
+
+	if parent.Valid {
+		subtree, err := DescendantIds(db, bucketId)
+		if err != nil {
+			return err
+		}
+		for _, id := range subtree {
+			if id == parentId {
+				return fmt.Errorf("Error, bucket %d can't be moved under itself or a bucket inside it", bucketId)
+			}
+		}
+	}
+
+	_, err := db.Exec("UPDATE Buckets SET parent_id = ? WHERE bucket_id = ?", parent, bucketId)
+	if err != nil {
+		return fmt.Errorf("Error moving bucket %d: %w", bucketId, err)
+	}
+
+	return nil
+}
+
+// GetTree arranges the bucket summaries into a tree, rolling task totals up
+// to every ancestor. Buckets whose parent is left out become roots.
+func GetTree(db *sqlx.DB, includeArchived bool) ([]*Node, error) {
+	summaries, err := GetSummaries(db, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]*Node, len(summaries))
+	for _, s := range summaries {
+		byId[s.BucketId] = &Node{Summary: s}
+	}
+
+	var roots []*Node
+	for _, s := range summaries {
+		node := byId[s.BucketId]
+		if parent, ok := byId[s.ParentId.Int64]; s.ParentId.Valid && ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	for _, root := range roots {
+		root.rollUp(0)
+	}
+
+	return roots, nil
+}
+
+func (n *Node) rollUp(depth int) {
+	n.Depth = depth
+	n.TreeTaskCount = n.TaskCount
+	n.TreeSeconds = n.TotalSeconds
+
+	for _, child := range n.Children {
+		child.rollUp(depth + 1)
+		n.TreeTaskCount += child.TreeTaskCount
+		n.TreeSeconds += child.TreeSeconds
+	}
+}
+
+// Flatten lists the tree depth first, parents before their children.
+func Flatten(roots []*Node) []*Node {
+	var out []*Node
+	for _, n := range roots {
+		out = append(out, n)
+		out = append(out, Flatten(n.Children)...)
+	}
+	return out
+}
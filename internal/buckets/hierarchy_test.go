@@ -0,0 +1,130 @@
+package buckets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/jmoiron/sqlx"
+)
+
+func addFinishedTask(t *testing.T, sqlDb *sqlx.DB, bucketId int64, seconds int) {
+	t.Helper()
+
+	task := tasks.NewTask("task", int64(seconds), false, false, time.Now())
+	task.AddBucketTag(bucketId)
+	if err := tasks.InsertTask(sqlDb, task); err != nil {
+		t.Fatal(err)
+	}
+	task.SetActualDuration(seconds)
+	task.Completed = 1
+	if err := tasks.UpdateTaskAsFinished(sqlDb, *task); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetTree(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	goal, _ := CreateBucket(sqlDb, "goal")
+	project, _ := CreateBucket(sqlDb, "project")
+	other, _ := CreateBucket(sqlDb, "other")
+	SetParent(sqlDb, project.BucketId, goal.BucketId)
+
+	addFinishedTask(t, sqlDb, goal.BucketId, 60)
+	addFinishedTask(t, sqlDb, project.BucketId, 120)
+	addFinishedTask(t, sqlDb, project.BucketId, 180)
+	addFinishedTask(t, sqlDb, other.BucketId, 30)
+
+	tree, err := GetTree(sqlDb, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		depth   int
+		count   int64
+		seconds int64
+	}{
+		{name: "goal", depth: 0, count: 3, seconds: 360},
+		{name: "project", depth: 1, count: 2, seconds: 300},
+		{name: "other", depth: 0, count: 1, seconds: 30},
+	}
+
+	flat := Flatten(tree)
+	if len(flat) != len(testCases) {
+		t.Fatalf("Expected: %v nodes, got: %v", len(testCases), len(flat))
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := flat[i]
+			if n.BucketName != tc.name || n.Depth != tc.depth {
+				t.Errorf("Expected: %v at depth %v, got: %v at depth %v", tc.name, tc.depth, n.BucketName, n.Depth)
+			}
+			if n.TreeTaskCount != tc.count || n.TreeSeconds != tc.seconds {
+				t.Errorf("Expected: %v tasks, %vs, got: %v tasks, %vs", tc.count, tc.seconds, n.TreeTaskCount, n.TreeSeconds)
+			}
+		})
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	goal, _ := CreateBucket(sqlDb, "goal")
+	project, _ := CreateBucket(sqlDb, "project")
+	if err := SetParent(sqlDb, project.BucketId, goal.BucketId); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetParent(sqlDb, goal.BucketId, goal.BucketId); err == nil {
+		t.Errorf("Expected moving a bucket under itself to fail")
+	}
+	if err := SetParent(sqlDb, goal.BucketId, project.BucketId); err == nil {
+		t.Errorf("Expected moving a bucket under its child to fail")
+	}
+
+	ancestors, err := AncestorIds(sqlDb, project.BucketId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ancestors) != 2 || ancestors[0] != project.BucketId || ancestors[1] != goal.BucketId {
+		t.Errorf("Expected: %v, got: %v", []int64{project.BucketId, goal.BucketId}, ancestors)
+	}
+
+	// deleting the goal moves the project up to the top level.
+	if err := DeleteBucket(sqlDb, goal.BucketId, false); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := GetBucketByID(sqlDb, project.BucketId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ParentId.Valid {
+		t.Errorf("Expected no parent, got: %v", moved.ParentId.Int64)
+	}
+}
+
+func TestGetProgressIncludesChildren(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	goal, _ := CreateBucket(sqlDb, "goal")
+	project, _ := CreateBucket(sqlDb, "project")
+	SetParent(sqlDb, project.BucketId, goal.BucketId)
+	SetBudget(sqlDb, goal.BucketId, time.Hour, PeriodWeek)
+
+	addFinishedTask(t, sqlDb, goal.BucketId, 600)
+	addFinishedTask(t, sqlDb, project.BucketId, 1200)
+
+	bucket, _ := GetBucketByID(sqlDb, goal.BucketId)
+	p, err := GetProgress(sqlDb, bucket, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 30 * time.Minute; p.Spent != want {
+		t.Errorf("Expected: %v, got: %v", want, p.Spent)
+	}
+}
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+const maxSkippedShown = 10
+
+var BlocklistCmd = &cli.Command{
+	Name:  "blocklist",
+	Usage: "Manage the domains in each block group.",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Add entries to a block group, creating it if needed. Entries may be exact (reddit.com), wildcards (*.reddit.com) or exceptions (!old.reddit.com).",
+			ArgsUsage: "[group] [domain...]",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 2 {
+					return errors.New("Error, expected a group and at least one domain")
+				}
+
+				group := ctx.Args().First()
+				groups := copyBlockGroups(config.GetBlockGroups())
+
+				existing := make(map[string]bool)
+				for _, domain := range groups[group] {
+					existing[domain] = true
+				}
+
+				var added []string
+				for _, arg := range ctx.Args().Tail() {
+					rule, err := blocker.ParseRule(arg)
+					if err != nil {
+						return err
+					}
+
+					domain := rule.String()
+					if existing[domain] {
+						continue
+					}
+
+					existing[domain] = true
+					groups[group] = append(groups[group], domain)
+					added = append(added, domain)
+				}
+
+				sort.Strings(groups[group])
+
+				if err := config.SetBlockGroups(groups); err != nil {
+					return err
+				}
+
+				fmt.Printf("Added %d domain(s) to %s.\n", len(added), group)
+				return nil
+			},
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove domains from a block group. Removing every domain deletes the group.",
+			ArgsUsage: "[group] [domain...]",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 2 {
+					return errors.New("Error, expected a group and at least one domain")
+				}
+
+				group := ctx.Args().First()
+				groups := copyBlockGroups(config.GetBlockGroups())
+
+				domains, ok := groups[group]
+				if !ok {
+					return fmt.Errorf("Error, unknown group %s", group)
+				}
+
+				remove := make(map[string]bool)
+				for _, arg := range ctx.Args().Tail() {
+					rule, err := blocker.ParseRule(arg)
+					if err != nil {
+						return err
+					}
+					remove[rule.String()] = true
+				}
+
+				var kept []string
+				for _, domain := range domains {
+					if !remove[domain] {
+						kept = append(kept, domain)
+					}
+				}
+
+				if len(kept) == 0 {
+					delete(groups, group)
+				} else {
+					groups[group] = kept
+				}
+
+				if err := config.SetBlockGroups(groups); err != nil {
+					return err
+				}
+
+				fmt.Printf("Removed %d domain(s) from %s.\n", len(domains)-len(kept), group)
+				return nil
+			},
+		},
+		{
+			Name:      "import",
+			Usage:     "Import a hosts, adblock or plain domains list into a block group.",
+			ArgsUsage: "[file]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "format",
+					Aliases:  []string{"f"},
+					Usage:    "List format: hosts, adblock or domains.",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "group",
+					Aliases:  []string{"g"},
+					Usage:    "Block group to import into.",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "sync",
+					Usage: "Re-import the file on each block start. The group's rules are replaced by the file's.",
+				},
+				&cli.BoolFlag{
+					Name:  "verbose",
+					Usage: "Print every skipped line.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a file to import")
+				}
+
+				path, err := filepath.Abs(ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				format := ctx.String("format")
+				group := ctx.String("group")
+
+				result, err := blocker.ImportFile(path, format)
+				if err != nil {
+					return err
+				}
+
+				groups := copyBlockGroups(config.GetBlockGroups())
+
+				var added int
+				if ctx.Bool("sync") {
+					added = len(result.Rules)
+					groups[group] = result.Rules
+				} else {
+					groups[group], added = blocker.MergeRules(groups[group], result.Rules)
+				}
+				sort.Strings(groups[group])
+
+				if err := config.SetBlockGroups(groups); err != nil {
+					return err
+				}
+
+				if ctx.Bool("sync") {
+					sources := []config.BlocklistSource{{Path: path, Format: format, Group: group}}
+					for _, source := range config.GetBlocklistSources() {
+						if source.Path != path || source.Group != group {
+							sources = append(sources, source)
+						}
+					}
+
+					if err := config.SetBlocklistSources(sources); err != nil {
+						return err
+					}
+				}
+
+				fmt.Printf("Imported %d rule(s) into %s (%d duplicate(s), %d skipped).\n", added, group, result.Duplicates, len(result.Skipped))
+
+				limit := len(result.Skipped)
+				if !ctx.Bool("verbose") && limit > maxSkippedShown {
+					limit = maxSkippedShown
+				}
+				for _, skipped := range result.Skipped[:limit] {
+					fmt.Printf("  line %d: %s (%s)\n", skipped.Line, skipped.Text, skipped.Reason)
+				}
+				if limit < len(result.Skipped) {
+					fmt.Printf("  ... and %d more, use --verbose to show all.\n", len(result.Skipped)-limit)
+				}
+
+				if ctx.Bool("sync") {
+					fmt.Printf("%s will be re-imported into %s on each block start.\n", path, group)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "list",
+			Usage:     "List block groups and their domains.",
+			ArgsUsage: "[group]",
+			Action: func(ctx *cli.Context) error {
+				groups := config.GetBlockGroups()
+
+				var names []string
+				if ctx.NArg() > 0 {
+					names = ctx.Args().Slice()
+				} else {
+					for name := range groups {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+				}
+
+				defaults := strings.Join(config.GetDefaultGroups(), ",")
+				if defaults == "" {
+					defaults = "all"
+				}
+				fmt.Println("Default groups:", defaults)
+
+				for _, name := range names {
+					domains, ok := groups[name]
+					if !ok {
+						return fmt.Errorf("Error, unknown group %s", name)
+					}
+
+					fmt.Printf("\n%s (%d)\n", name, len(domains))
+					for _, domain := range domains {
+						fmt.Println("  " + domain)
+					}
+				}
+
+				return nil
+			},
+		},
+	},
+}
+
+// syncBlocklists re-imports configured blocklist sources, warning rather than
+// failing so a missing file never prevents a session from starting.
+func syncBlocklists() {
+	results, err := blocker.SyncSources()
+	if err != nil {
+		slog.Warn("Unable to sync blocklists.", "error", err)
+		return
+	}
+
+	for path, result := range results {
+		slog.Info("Synced blocklist.", "path", path, "rules", len(result.Rules), "skipped", len(result.Skipped))
+	}
+}
+
+func copyBlockGroups(groups map[string][]string) map[string][]string {
+	out := make(map[string][]string, len(groups))
+	for name, domains := range groups {
+		out[name] = append([]string(nil), domains...)
+	}
+	return out
+}
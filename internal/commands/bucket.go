@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/buckets"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var BucketCmd = &cli.Command{
+	Name:  "bucket",
+	Usage: "Manage the buckets tasks are grouped into with start --bucket.",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "create",
+			Usage:     "Create a bucket.",
+			ArgsUsage: "[name]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "parent",
+					Aliases: []string{"p"},
+					Usage:   "Create the bucket under another, e.g. a project under a goal.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a bucket name")
+				}
+
+				var parent buckets.Bucket
+				if ctx.IsSet("parent") {
+					var err error
+					parent, err = buckets.ResolveActive(db, ctx.String("parent"))
+					if err != nil {
+						return err
+					}
+				}
+
+				bucket, err := buckets.CreateBucket(db, ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				if parent.BucketId != 0 {
+					if err := buckets.SetParent(db, bucket.BucketId, parent.BucketId); err != nil {
+						return err
+					}
+					fmt.Printf("Created bucket %s (%d) under %s.\n", bucket.BucketName, bucket.BucketId, parent.BucketName)
+					return nil
+				}
+
+				fmt.Printf("Created bucket %s (%d).\n", bucket.BucketName, bucket.BucketId)
+				return nil
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "List buckets as a tree, with task totals including the buckets under each.",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "all",
+					Aliases: []string{"a"},
+					Usage:   "Include archived buckets.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				tree, err := buckets.GetTree(db, ctx.Bool("all"))
+				if err != nil {
+					return err
+				}
+
+				if len(tree) == 0 {
+					fmt.Println("No buckets, create one with block bucket create.")
+					return nil
+				}
+
+				for _, n := range buckets.Flatten(tree) {
+					var archived string
+					if n.Archived() {
+						archived = " (archived)"
+					}
+					indent := strings.Repeat("  ", n.Depth)
+					fmt.Printf("%s%d: %s%s, %d tasks, %s\n", indent, n.BucketId, n.BucketName, archived, n.TreeTaskCount, utils.SecsToHHMMSS(n.TreeSeconds))
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "move",
+			Usage:     "Move a bucket under another bucket.",
+			ArgsUsage: "[name] [parent]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "root",
+					Usage: "Move the bucket to the top level instead.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				root := ctx.Bool("root")
+				if ctx.NArg() < 1 || (!root && ctx.NArg() < 2) {
+					return errors.New("Error, expected a bucket and its new parent, or --root")
+				}
+
+				bucket, err := buckets.Resolve(db, ctx.Args().Get(0))
+				if err != nil {
+					return err
+				}
+
+				if root {
+					if err := buckets.SetParent(db, bucket.BucketId, 0); err != nil {
+						return err
+					}
+					fmt.Printf("Moved bucket %s to the top level.\n", bucket.BucketName)
+					return nil
+				}
+
+				parent, err := buckets.Resolve(db, ctx.Args().Get(1))
+				if err != nil {
+					return err
+				}
+
+				if err := buckets.SetParent(db, bucket.BucketId, parent.BucketId); err != nil {
+					return err
+				}
+
+				fmt.Printf("Moved bucket %s under %s.\n", bucket.BucketName, parent.BucketName)
+				return nil
+			},
+		},
+		{
+			Name:      "rename",
+			Usage:     "Rename a bucket.",
+			ArgsUsage: "[name] [new name]",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 2 {
+					return errors.New("Error, expected a bucket and its new name")
+				}
+
+				bucket, err := buckets.Resolve(db, ctx.Args().Get(0))
+				if err != nil {
+					return err
+				}
+
+				if err := buckets.RenameBucket(db, bucket.BucketId, ctx.Args().Get(1)); err != nil {
+					return err
+				}
+
+				fmt.Printf("Renamed bucket %s to %s.\n", bucket.BucketName, ctx.Args().Get(1))
+				return nil
+			},
+		},
+		{
+			Name:      "archive",
+			Usage:     "Archive a bucket, hiding it from lists and new tasks but keeping its history.",
+			ArgsUsage: "[name]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "restore",
+					Usage: "Bring an archived bucket back.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a bucket name")
+				}
+
+				bucket, err := buckets.Resolve(db, ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				restore := ctx.Bool("restore")
+				if err := buckets.SetArchived(db, bucket.BucketId, !restore); err != nil {
+					return err
+				}
+
+				if restore {
+					fmt.Printf("Restored bucket %s.\n", bucket.BucketName)
+				} else {
+					fmt.Printf("Archived bucket %s.\n", bucket.BucketName)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "budget",
+			Usage:     "Set how much time to spend in a bucket each day, week or month.",
+			ArgsUsage: "[name] [duration]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "per",
+					Value: "week",
+					Usage: "Period the budget resets over: day, week or month.",
+				},
+				&cli.BoolFlag{
+					Name:  "clear",
+					Usage: "Remove the bucket's budget.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a bucket name")
+				}
+
+				bucket, err := buckets.Resolve(db, ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				if ctx.Bool("clear") {
+					if err := buckets.ClearBudget(db, bucket.BucketId); err != nil {
+						return err
+					}
+					fmt.Printf("Cleared budget of bucket %s.\n", bucket.BucketName)
+					return nil
+				}
+
+				if ctx.NArg() < 2 {
+					return errors.New("Error, expected a budget, e.g. 10h")
+				}
+
+				target, err := timeparse.ParseDuration(ctx.Args().Get(1))
+				if err != nil {
+					return err
+				}
+
+				period, err := buckets.ParsePeriod(ctx.String("per"))
+				if err != nil {
+					return err
+				}
+
+				if err := buckets.SetBudget(db, bucket.BucketId, target, period); err != nil {
+					return err
+				}
+
+				fmt.Printf("Budget of bucket %s is %s %s.\n", bucket.BucketName, target, period.Adverb())
+				return nil
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "Show time spent against each bucket's budget this period.",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				progress, err := buckets.GetAllProgress(db, time.Now())
+				if err != nil {
+					return err
+				}
+
+				if len(progress) == 0 {
+					fmt.Println("No budgets, set one with block bucket budget.")
+					return nil
+				}
+
+				sorted := make([]buckets.Progress, 0, len(progress))
+				for _, p := range progress {
+					sorted = append(sorted, p)
+				}
+				sort.Slice(sorted, func(i, j int) bool {
+					return sorted[i].Bucket.BucketName < sorted[j].Bucket.BucketName
+				})
+
+				for _, p := range sorted {
+					var over string
+					if p.Over() {
+						over = " (over)"
+					}
+					fmt.Printf("%s: %s of %s %s, %.0f%%%s\n", p.Bucket.BucketName, utils.SecsToHHMMSS(p.SpentSeconds()), utils.SecsToHHMMSS(p.TargetSeconds()), p.Period.Adverb(), p.Percent(), over)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "delete",
+			Usage:     "Delete a bucket without tasks, moving the buckets under it up a level.",
+			ArgsUsage: "[name]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Untag the bucket's tasks and delete it anyway.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a bucket name")
+				}
+
+				bucket, err := buckets.Resolve(db, ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				if err := buckets.DeleteBucket(db, bucket.BucketId, ctx.Bool("force")); err != nil {
+					return err
+				}
+
+				fmt.Printf("Deleted bucket %s.\n", bucket.BucketName)
+				return nil
+			},
+		},
+	},
+}
+
+// resolveBucketFlag returns the id of the active bucket named by --bucket, or
+// 0 if it wasn't given.
+func resolveBucketFlag(ctx *cli.Context, db *sqlx.DB) (int64, error) {
+	if !ctx.IsSet("bucket") {
+		return 0, nil
+	}
+
+	bucket, err := buckets.ResolveActive(db, ctx.String("bucket"))
+	if err != nil {
+		return 0, err
+	}
+
+	return bucket.BucketId, nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/schedule"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var DaemonCmd = &cli.Command{
+	Name:  "daemon",
+	Usage: "Apply and lift blocks on the schedules in config, recording each window as a task.",
+	Action: func(ctx *cli.Context) error {
+		db := ctx.Context.Value("db").(*sqlx.DB)
+
+		rules, err := schedule.ParseRules(config.GetSchedules())
+		if err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			fmt.Println("No schedules configured, add one with `block schedule add`.")
+		}
+
+		syncBlocklists()
+
+		d := &schedule.Daemon{
+			Db: db,
+			Rules: func() ([]schedule.Rule, error) {
+				if err := config.ReloadConfig(); err != nil {
+					return nil, err
+				}
+				return schedule.ParseRules(config.GetSchedules())
+			},
+			NewBlocker: func(groups []string) (blocker.Blocker, error) {
+				return blocker.New(blocker.Options{Groups: groups})
+			},
+		}
+
+		runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer stop()
+
+		fmt.Printf("Running %d schedule(s), press ctrl-c to stop.\n", len(rules))
+		if err := d.Run(runCtx); err != nil {
+			return fmt.Errorf("Error running daemon: %w", err)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/connorkuljis/block-cli/internal/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var DbCmd = &cli.Command{
+	Name:  "db",
+	Usage: "Manage the database schema.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "migrate",
+			Usage: "Apply pending schema migrations.",
+			Action: func(ctx *cli.Context) error {
+				sqlDb := ctx.Context.Value("db").(*sqlx.DB)
+
+				applied, err := db.Migrate(sqlDb)
+				if err != nil {
+					return err
+				}
+
+				if len(applied) == 0 {
+					fmt.Println("Database schema is up to date.")
+					return nil
+				}
+
+				for _, m := range applied {
+					fmt.Printf("Applied migration %04d_%s.\n", m.Version, m.Name)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "List schema migrations and whether they are applied.",
+			Action: func(ctx *cli.Context) error {
+				sqlDb := ctx.Context.Value("db").(*sqlx.DB)
+
+				statuses, err := db.GetMigrationStatus(sqlDb)
+				if err != nil {
+					return err
+				}
+
+				for _, s := range statuses {
+					if s.Applied {
+						fmt.Printf("%04d_%s\tapplied %s\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+					} else {
+						fmt.Printf("%04d_%s\tpending\n", s.Version, s.Name)
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:  "rollback",
+			Usage: "Revert the most recently applied schema migration.",
+			Action: func(ctx *cli.Context) error {
+				sqlDb := ctx.Context.Value("db").(*sqlx.DB)
+
+				m, err := db.Rollback(sqlDb)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("Reverted migration %04d_%s.\n", m.Version, m.Name)
+				return nil
+			},
+		},
+	},
+}
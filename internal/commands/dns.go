@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/dns"
+	"github.com/urfave/cli/v2"
+)
+
+var DNSCmd = &cli.Command{
+	Name:  "dns",
+	Usage: "Run the local DNS sinkhole used by the dns blocker backend.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "serve",
+			Usage: "Forward DNS queries upstream, sinkholing blocked domains during a session.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "listen",
+					Usage: "Local address to listen on (defaults to dns.listen in config).",
+				},
+				&cli.StringFlag{
+					Name:  "upstream",
+					Usage: "Upstream resolver to forward to (defaults to dns.upstream in config).",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				cfg := config.GetDNSConfig()
+				if listen := ctx.String("listen"); listen != "" {
+					cfg.Listen = listen
+				}
+				if upstream := ctx.String("upstream"); upstream != "" {
+					cfg.Upstream = upstream
+				}
+
+				if cfg.Response != dns.ResponseNXDomain && cfg.Response != dns.ResponseZero {
+					return fmt.Errorf("Error, invalid dns.response %q (expected nxdomain or zero)", cfg.Response)
+				}
+
+				blocklist := dns.NewLeaseBlocklist(config.GetLeasePath())
+				server := &dns.Server{
+					Addr:     cfg.Listen,
+					Upstream: cfg.Upstream,
+					Response: cfg.Response,
+					Blocked:  blocklist.Blocked,
+				}
+
+				sig := make(chan os.Signal, 1)
+				signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+				go func() {
+					<-sig
+					server.Close()
+				}()
+
+				if config.GetBlockerBackend() != blocker.BackendDNS {
+					slog.Warn("The dns sinkhole only blocks sessions started with `blocker: dns` in config.")
+				}
+
+				fmt.Printf("Serving DNS on %s, forwarding to %s.\n", cfg.Listen, cfg.Upstream)
+				return server.ListenAndServe()
+			},
+		},
+		{
+			Name:      "check",
+			Usage:     "Report whether the sinkhole is currently blocking a domain.",
+			ArgsUsage: "[domain]",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a domain")
+				}
+
+				domain, err := blocker.NormaliseDomain(ctx.Args().First())
+				if err != nil {
+					return err
+				}
+
+				blocklist := dns.NewLeaseBlocklist(config.GetLeasePath())
+				if blocklist.Blocked(domain) {
+					fmt.Printf("%s is blocked.\n", domain)
+				} else {
+					fmt.Printf("%s is not blocked.\n", domain)
+				}
+
+				return nil
+			},
+		},
+	},
+}
@@ -3,8 +3,10 @@ package commands
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
 	"github.com/urfave/cli/v2"
 )
 
@@ -12,8 +14,29 @@ var DownCmd = &cli.Command{
 	Name:  "down",
 	Usage: "disable the blocker",
 	Action: func(ctx *cli.Context) error {
+		lease, err := blocker.ReadLease(config.GetLeasePath())
+		if err != nil {
+			return fmt.Errorf("Error running down command: %w", err)
+		}
+
+		if lease != nil && lease.Locked(time.Now()) {
+			return lease.LockedError()
+		}
+
 		slog.Info("Blocker down.")
-		blocker := blocker.NewBlocker()
+
+		// the block may belong to any process, so it is lifted through its lease.
+		if lease != nil {
+			if _, err := blocker.Recover(); err != nil {
+				return fmt.Errorf("Error running down command: %w", err)
+			}
+			return nil
+		}
+
+		blocker, err := blocker.New(blocker.Options{})
+		if err != nil {
+			return fmt.Errorf("Error running down command: %w", err)
+		}
 		n, err := blocker.Stop()
 		if err != nil {
 			return fmt.Errorf("Error running down command: %w", err)
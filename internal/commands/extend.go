@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"github.com/connorkuljis/block-cli/internal/control"
+	"github.com/connorkuljis/block-cli/internal/interactive"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
+	"github.com/urfave/cli/v2"
+)
+
+var ExtendCmd = &cli.Command{
+	Name:      "extend",
+	Usage:     "Add time to the running session, or take it off with --shorten.",
+	Args:      true,
+	ArgsUsage: "[duration]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "shorten",
+			Aliases: []string{"s"},
+			Usage:   "Take the time off the estimate instead.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		d := interactive.ExtendStep
+		if ctx.NArg() > 0 {
+			var err error
+			d, err = timeparse.ParseDuration(ctx.Args().Get(0))
+			if err != nil {
+				return err
+			}
+		}
+
+		seconds := int64(d.Seconds())
+		if ctx.Bool("shorten") {
+			seconds = -seconds
+		}
+
+		return sendControl(control.Request{Command: control.CommandExtend, Seconds: seconds})
+	},
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/connorkuljis/block-cli/internal/buckets"
 	"github.com/connorkuljis/block-cli/internal/tasks"
 	"github.com/jmoiron/sqlx"
 	"github.com/urfave/cli/v2"
@@ -13,6 +14,35 @@ import (
 var HistoryCmd = &cli.Command{
 	Name:  "history",
 	Usage: "display task history.",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Only show strict sessions.",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tag",
+			Aliases: []string{"t"},
+			Usage:   "Only show tasks with every given tag.",
+		},
+		&cli.IntFlag{
+			Name:  "min-rating",
+			Usage: "Only show tasks rated at least this.",
+		},
+		&cli.StringFlag{
+			Name:  "note",
+			Usage: "Only show tasks whose note contains this text.",
+		},
+		&cli.StringSliceFlag{
+			Name:    "status",
+			Aliases: []string{"s"},
+			Usage:   "Only show tasks with any of these statuses, e.g. cancelled,abandoned.",
+		},
+		&cli.StringFlag{
+			Name:    "bucket",
+			Aliases: []string{"b"},
+			Usage:   "Only show tasks in a bucket or the buckets under it, by name or id.",
+		},
+	},
 	Action: func(ctx *cli.Context) error {
 		db := ctx.Context.Value("db").(*sqlx.DB)
 
@@ -47,7 +77,55 @@ var HistoryCmd = &cli.Command{
 			}
 		}
 
-		tasks.RenderTable(all)
+		if ctx.Bool("strict") {
+			all = tasks.FilterStrict(all)
+		}
+
+		if err := tasks.AttachTags(db, all); err != nil {
+			return err
+		}
+
+		if err := tasks.AttachOverrides(db, all); err != nil {
+			return err
+		}
+
+		all, err := tasks.FoldSegments(db, all)
+		if err != nil {
+			return err
+		}
+
+		var bucketIds []int64
+		if ctx.IsSet("bucket") {
+			bucket, err := buckets.Resolve(db, ctx.String("bucket"))
+			if err != nil {
+				return err
+			}
+
+			bucketIds, err = buckets.DescendantIds(db, bucket.BucketId)
+			if err != nil {
+				return err
+			}
+		}
+
+		statuses, err := tasks.ParseStatuses(ctx.StringSlice("status"))
+		if err != nil {
+			return err
+		}
+
+		all = tasks.FilterTasks(all, tasks.Filter{
+			Tags:      ctx.StringSlice("tag"),
+			MinRating: ctx.Int("min-rating"),
+			Note:      ctx.String("note"),
+			BucketIds: bucketIds,
+			Statuses:  statuses,
+		})
+
+		pauses, err := tasks.GetPausesByTask(db, all)
+		if err != nil {
+			return err
+		}
+
+		tasks.RenderTable(all, pauses)
 
 		return nil
 	},
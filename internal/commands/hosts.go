@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/urfave/cli/v2"
+)
+
+var HostsCmd = &cli.Command{
+	Name:  "hosts",
+	Usage: "Manage hosts file backups.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "backups",
+			Usage: "List hosts file backups, newest first.",
+			Action: func(ctx *cli.Context) error {
+				blocker, err := blocker.NewHostsBlocker(blocker.Options{})
+				if err != nil {
+					return err
+				}
+
+				backups, err := blocker.Backups()
+				if err != nil {
+					return err
+				}
+
+				if len(backups) == 0 {
+					fmt.Println("No backups found.")
+					return nil
+				}
+
+				for _, backup := range backups {
+					fmt.Printf("%s\t%s\n", backup.Name, backup.CreatedAt.Format("Mon Jan 02 15:04:05"))
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "restore",
+			Usage:     "Restore the hosts file from a backup (defaults to the newest).",
+			ArgsUsage: "[backup]",
+			Action: func(ctx *cli.Context) error {
+				blocker, err := blocker.NewHostsBlocker(blocker.Options{})
+				if err != nil {
+					return err
+				}
+
+				backup, n, err := blocker.RestoreBackup(ctx.Args().First())
+				if err != nil {
+					return fmt.Errorf("Error running restore command: %w", err)
+				}
+
+				fmt.Printf("Restored hosts file from %s (%d bytes written).\n", backup.Name, n)
+				return nil
+			},
+		},
+	},
+}
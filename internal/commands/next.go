@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/connorkuljis/block-cli/internal/app"
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var NextCmd = &cli.Command{
+	Name:  "next",
+	Usage: "Start the next task in the plan.",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Block groups to apply, e.g. social,news (defaults to defaultGroups in config).",
+		},
+		&cli.BoolFlag{
+			Name:  "no-review",
+			Usage: "Don't ask for an outcome note and rating when the session ends.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		db := ctx.Context.Value("db").(*sqlx.DB)
+
+		task, err := tasks.GetNextPlannedTask(db)
+		if err != nil {
+			return err
+		}
+
+		var review io.Reader
+		if !ctx.Bool("no-review") && app.IsTerminal(os.Stdin) {
+			review = os.Stdin
+		}
+
+		var b blocker.Blocker
+		if task.BlockerEnabled == 1 {
+			syncBlocklists()
+			b, err = blocker.New(blocker.Options{
+				Groups: ctx.StringSlice("groups"),
+				Expiry: app.LeaseExpiry(&task),
+				Strict: task.Strict == 1,
+			})
+		} else {
+			b, err = blocker.NewNoopBlocker(blocker.Options{})
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Starting %s.\n", displayName(task.TaskName))
+		if err := app.Start(os.Stdout, db, &task, b, review); err != nil {
+			return err
+		}
+
+		fmt.Println("---")
+		next, err := tasks.GetNextPlannedTask(db)
+		switch {
+		case err == nil:
+			fmt.Printf("Up next: %s, run block next when ready.\n", displayName(next.TaskName))
+		case errors.Is(err, tasks.ErrNothingPlanned):
+			fmt.Println("That was the last planned task.")
+		default:
+			return err
+		}
+		fmt.Println("Goodbye.")
+
+		return nil
+	},
+}
@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"github.com/connorkuljis/block-cli/internal/control"
+	"github.com/urfave/cli/v2"
+)
+
+var PauseCmd = &cli.Command{
+	Name:  "pause",
+	Usage: "Pause the running session, lifting the block until it resumes.",
+	Action: func(ctx *cli.Context) error {
+		return sendControl(control.Request{Command: control.CommandPause})
+	},
+}
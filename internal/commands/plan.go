@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var PlanCmd = &cli.Command{
+	Name:  "plan",
+	Usage: "Plan the day's tasks ahead, then run them in order with block next.",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Add a task to the end of the plan.",
+			ArgsUsage: "[duration] [taskname...], or [taskname...] [duration]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "no-blocker",
+					Usage: "Run the task without the blocker.",
+				},
+				&cli.BoolFlag{
+					Name:    "capture",
+					Aliases: []string{"c"},
+					Usage:   "Enables screen capture.",
+				},
+				&cli.BoolFlag{
+					Name:  "strict",
+					Usage: "Run the task as a strict session.",
+				},
+				&cli.StringSliceFlag{
+					Name:    "tag",
+					Aliases: []string{"t"},
+					Usage:   "Tag the task.",
+				},
+				&cli.StringFlag{
+					Name:    "bucket",
+					Aliases: []string{"b"},
+					Usage:   "Tag the task with a bucket, by name or id.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				args, err := timeparse.ParsePlanArgs(ctx.Args().Slice())
+				if err != nil {
+					return err
+				}
+
+				blockerEnabled := !ctx.Bool("no-blocker")
+				if ctx.Bool("strict") && !blockerEnabled {
+					return errors.New("Error, --strict needs the blocker enabled")
+				}
+
+				bucketId, err := resolveBucketFlag(ctx, db)
+				if err != nil {
+					return err
+				}
+
+				task := tasks.NewTask(args.Name, int64(args.Duration.Seconds()), blockerEnabled, ctx.Bool("capture"), time.Now())
+				if bucketId != 0 {
+					task.AddBucketTag(bucketId)
+				}
+				if ctx.Bool("strict") {
+					task.SetStrict()
+				}
+				task.Tags = tasks.NormaliseTags(ctx.StringSlice("tag"))
+
+				if err := tasks.PlanTask(db, task); err != nil {
+					return err
+				}
+
+				planned, err := tasks.GetPlannedTasks(db)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("Planned %s for %s (#%d in the plan).\n", displayName(task.TaskName), args.Duration, len(planned))
+				return nil
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "List the plan in the order block next runs it.",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				planned, err := tasks.GetPlannedTasks(db)
+				if err != nil {
+					return err
+				}
+
+				if len(planned) == 0 {
+					fmt.Println("Nothing planned, add tasks with block plan add.")
+					return nil
+				}
+
+				if err := tasks.AttachTags(db, planned); err != nil {
+					return err
+				}
+
+				var total int64
+				for i, task := range planned {
+					var details []string
+					if task.BlockerEnabled == 0 {
+						details = append(details, "no blocker")
+					}
+					if task.Strict == 1 {
+						details = append(details, "strict")
+					}
+					if len(task.Tags) > 0 {
+						details = append(details, "tags "+strings.Join(task.Tags, ","))
+					}
+
+					var extra string
+					if len(details) > 0 {
+						extra = " (" + strings.Join(details, ", ") + ")"
+					}
+
+					fmt.Printf("%d. [%d] %s, %s%s\n", i+1, task.TaskId, displayName(task.TaskName), utils.SecsToHHMMSS(task.EstimatedDurationSeconds.Int64), extra)
+					total += task.EstimatedDurationSeconds.Int64
+				}
+				fmt.Println("---")
+				fmt.Println("Total planned ==>", utils.SecsToHHMMSS(total))
+
+				return nil
+			},
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove a task from the plan, by the id shown in block plan list.",
+			ArgsUsage: "[task id]",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a task id")
+				}
+
+				taskId, err := strconv.ParseInt(ctx.Args().First(), 10, 64)
+				if err != nil {
+					return fmt.Errorf("Error parsing task id: %w", err)
+				}
+
+				if err := tasks.UnplanTask(db, taskId); err != nil {
+					return err
+				}
+
+				fmt.Printf("Removed task %d from the plan.\n", taskId)
+				return nil
+			},
+		},
+	},
+}
+
+// displayName quotes a task name, which may be empty.
+func displayName(name string) string {
+	if name == "" {
+		return "untitled task"
+	}
+	return strconv.Quote(name)
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/app"
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/interactive"
+	"github.com/connorkuljis/block-cli/internal/pomodoro"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var PomodoroCmd = &cli.Command{
+	Name:      "pomodoro",
+	Usage:     "run focus sessions back to back with breaks in between.",
+	Args:      true,
+	ArgsUsage: "[taskname...]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "work",
+			Value: "25",
+			Usage: "Length of each focus session, minutes or e.g. 25m.",
+		},
+		&cli.StringFlag{
+			Name:  "break",
+			Value: "5",
+			Usage: "Length of each break.",
+		},
+		&cli.StringFlag{
+			Name:  "long-break",
+			Value: "15",
+			Usage: "Length of the long break, taken every --long-break-every focus sessions and after the last.",
+		},
+		&cli.IntFlag{
+			Name:  "long-break-every",
+			Value: 4,
+			Usage: "Number of focus sessions between long breaks.",
+		},
+		&cli.IntFlag{
+			Name:  "cycles",
+			Value: 4,
+			Usage: "Number of focus sessions.",
+		},
+		&cli.BoolFlag{
+			Name:  "no-blocker",
+			Usage: "Disables the blocker.",
+		},
+		&cli.StringSliceFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Block groups to apply, e.g. social,news (defaults to defaultGroups in config).",
+		},
+		&cli.StringFlag{
+			Name:    "bucket",
+			Aliases: []string{"b"},
+			Usage:   "Tag every focus session with a bucket, by name or id.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		db := ctx.Context.Value("db").(*sqlx.DB)
+
+		name := timeparse.ParseName(ctx.Args().Slice()) // empty string is ok.
+		blockerEnabled := !ctx.Bool("no-blocker")
+		bucketId, err := resolveBucketFlag(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		plan := pomodoro.Plan{Rounds: ctx.Int("cycles"), LongBreakEvery: ctx.Int("long-break-every")}
+		for flag, d := range map[string]*time.Duration{"work": &plan.Work, "break": &plan.Break, "long-break": &plan.LongBreak} {
+			// a break of 0 skips it, a focus session can't be skipped.
+			if value := ctx.String(flag); flag == "work" || value != "0" {
+				var err error
+				*d, err = timeparse.ParseDuration(value)
+				if err != nil {
+					return fmt.Errorf("Error parsing --%s: %w", flag, err)
+				}
+			}
+		}
+		if err := plan.Validate(); err != nil {
+			return err
+		}
+
+		cycle := &tasks.Cycle{
+			CycleName:        name,
+			CreatedAt:        time.Now(),
+			WorkSeconds:      int64(plan.Work.Seconds()),
+			BreakSeconds:     int64(plan.Break.Seconds()),
+			LongBreakSeconds: int64(plan.LongBreak.Seconds()),
+			Rounds:           plan.Rounds,
+		}
+		if err := tasks.InsertCycle(db, cycle); err != nil {
+			return fmt.Errorf("Error creating pomodoro cycle: %w", err)
+		}
+
+		if blockerEnabled {
+			syncBlocklists()
+		}
+
+		p := &pomodoro.Pomodoro{
+			Plan:   plan,
+			Notify: utils.Notify,
+			// each focus phase is its own task, blocked for its duration
+			// only, so the block is lifted for every break.
+			Focus: func(phase pomodoro.Phase) (bool, error) {
+				task := tasks.NewTask(name, int64(phase.Duration.Seconds()), blockerEnabled, false, time.Now())
+				task.SetCycle(cycle.CycleId)
+				if bucketId != 0 {
+					task.AddBucketTag(bucketId)
+				}
+
+				var b blocker.Blocker
+				var err error
+				if blockerEnabled {
+					b, err = blocker.New(blocker.Options{
+						Groups: ctx.StringSlice("groups"),
+						Expiry: phase.Duration + blocker.LeaseGrace,
+					})
+				} else {
+					b, err = blocker.NewNoopBlocker(blocker.Options{})
+				}
+				if err != nil {
+					return false, err
+				}
+
+				fmt.Printf("Focus %d of %d.\n", phase.Round, plan.Rounds)
+				if err := app.Start(os.Stdout, db, task, b, nil); err != nil {
+					return false, err
+				}
+				return task.Completed == 1, nil
+			},
+			Break: func(phase pomodoro.Phase) error {
+				fmt.Printf("Break after focus %d.\n", phase.Round)
+				interactive.RunBreak(os.Stdout, string(phase.Kind), phase.Duration)
+				return nil
+			},
+		}
+
+		completed, err := p.Run()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("---")
+		fmt.Printf("Completed %d of %d focus sessions (cycle %d).\n", completed, plan.Rounds, cycle.CycleId)
+		fmt.Println("Goodbye.")
+
+		return nil
+	},
+}
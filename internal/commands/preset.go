@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/connorkuljis/block-cli/internal/buckets"
+	"github.com/connorkuljis/block-cli/internal/presets"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var PresetCmd = &cli.Command{
+	Name:  "preset",
+	Usage: "Manage presets for recurring sessions, started with block start @name.",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "save",
+			Usage:     "Save a preset, replacing any with the same name.",
+			ArgsUsage: "[name] [duration] [taskname...]",
+			Flags: []cli.Flag{
+				&cli.Int64Flag{
+					Name:  "from-task",
+					Usage: "Copy the duration, name, bucket, tags and flags of a past task, by id.",
+				},
+				&cli.BoolFlag{
+					Name:  "no-blocker",
+					Usage: "Disables the blocker.",
+				},
+				&cli.BoolFlag{
+					Name:    "capture",
+					Aliases: []string{"c"},
+					Usage:   "Enables screen capture.",
+				},
+				&cli.StringSliceFlag{
+					Name:    "groups",
+					Aliases: []string{"g"},
+					Usage:   "Block groups to apply, e.g. social,news.",
+				},
+				&cli.BoolFlag{
+					Name:    "stopwatch",
+					Aliases: []string{"s"},
+					Usage:   "Count up without an estimate.",
+				},
+				&cli.BoolFlag{
+					Name:  "strict",
+					Usage: "Start strict sessions.",
+				},
+				&cli.StringSliceFlag{
+					Name:    "tag",
+					Aliases: []string{"t"},
+					Usage:   "Tag the tasks started from the preset.",
+				},
+				&cli.StringFlag{
+					Name:    "bucket",
+					Aliases: []string{"b"},
+					Usage:   "Bucket for the tasks started from the preset, by name or id.",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a preset name")
+				}
+				args := ctx.Args().Slice()
+
+				preset := presets.Preset{BlockerEnabled: 1}
+				if ctx.IsSet("from-task") {
+					task, err := tasks.GetTaskByID(db, ctx.Int64("from-task"))
+					if err != nil {
+						return fmt.Errorf("Error getting task %d: %w", ctx.Int64("from-task"), err)
+					}
+					withTags := []tasks.Task{task}
+					if err := tasks.AttachTags(db, withTags); err != nil {
+						return err
+					}
+					preset = presets.FromTask(args[0], withTags[0])
+				}
+				preset.PresetName = args[0]
+
+				if len(args) > 1 {
+					d, err := timeparse.ParseDuration(args[1])
+					if err != nil {
+						return err
+					}
+					preset.DurationSeconds.Int64, preset.DurationSeconds.Valid = int64(d.Seconds()), true
+				}
+				if len(args) > 2 {
+					preset.TaskName = timeparse.ParseName(args[2:])
+				}
+
+				if ctx.IsSet("no-blocker") {
+					preset.BlockerEnabled = utils.BoolToInt(!ctx.Bool("no-blocker"))
+				}
+				for flag, value := range map[string]*int{"capture": &preset.ScreenEnabled, "stopwatch": &preset.Stopwatch, "strict": &preset.Strict} {
+					if ctx.IsSet(flag) {
+						*value = utils.BoolToInt(ctx.Bool(flag))
+					}
+				}
+				if ctx.IsSet("groups") {
+					preset.BlockGroups = strings.Join(ctx.StringSlice("groups"), ",")
+				}
+				if ctx.IsSet("tag") {
+					preset.Tags = strings.Join(tasks.NormaliseTags(ctx.StringSlice("tag")), ",")
+				}
+				if ctx.IsSet("bucket") {
+					bucket, err := buckets.ResolveActive(db, ctx.String("bucket"))
+					if err != nil {
+						return err
+					}
+					preset.BucketId.Int64, preset.BucketId.Valid = bucket.BucketId, true
+				}
+
+				if err := presets.SavePreset(db, &preset); err != nil {
+					return err
+				}
+
+				fmt.Printf("Saved preset %s%s: %s\n", presets.Prefix, preset.PresetName, preset.Describe())
+				return nil
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "List saved presets.",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				all, err := presets.GetAllPresets(db)
+				if err != nil {
+					return err
+				}
+
+				if len(all) == 0 {
+					fmt.Println("No presets, save one with block preset save.")
+					return nil
+				}
+
+				for _, p := range all {
+					fmt.Printf("%s%s: %s\n", presets.Prefix, p.PresetName, p.Describe())
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "delete",
+			Usage:     "Delete a preset.",
+			ArgsUsage: "[name]",
+			Action: func(ctx *cli.Context) error {
+				db := ctx.Context.Value("db").(*sqlx.DB)
+
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a preset name")
+				}
+
+				if err := presets.DeletePreset(db, ctx.Args().First()); err != nil {
+					return err
+				}
+
+				fmt.Printf("Deleted preset %s.\n", ctx.Args().First())
+				return nil
+			},
+		},
+	},
+}
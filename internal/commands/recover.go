@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+var RecoverCmd = &cli.Command{
+	Name:  "recover",
+	Usage: "Lift a block left behind by a crashed session.",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "Lift the block even if the process holding it is still running.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		lease, err := blocker.ReadLease(config.GetLeasePath())
+		if err != nil {
+			return err
+		}
+
+		if lease == nil {
+			fmt.Println("No active block, nothing to recover.")
+			return nil
+		}
+
+		if lease.Locked(time.Now()) {
+			return lease.LockedError()
+		}
+
+		if !lease.Orphaned(time.Now()) && !ctx.Bool("force") {
+			return fmt.Errorf("Block held by running process %d since %s, use --force to lift it anyway", lease.PID, lease.StartedAt.Format(time.Kitchen))
+		}
+
+		_, err = blocker.Recover()
+		if err != nil {
+			return fmt.Errorf("Error running recover command: %w", err)
+		}
+
+		fmt.Printf("Lifted %s block started at %s.\n", lease.Backend, lease.StartedAt.Format(time.Kitchen))
+		return nil
+	},
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/app"
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/control"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/jmoiron/sqlx"
+	"github.com/urfave/cli/v2"
+)
+
+var ResumeCmd = &cli.Command{
+	Name:      "resume",
+	Usage:     "Resume the paused session, or continue a cancelled task for the rest of its estimate.",
+	ArgsUsage: "[task-id]",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Block groups to apply, e.g. social,news (defaults to defaultGroups in config).",
+		},
+		&cli.BoolFlag{
+			Name:  "no-review",
+			Usage: "Don't ask for an outcome note and rating when the session ends.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() == 0 {
+			return sendControl(control.Request{Command: control.CommandResume})
+		}
+
+		db := ctx.Context.Value("db").(*sqlx.DB)
+
+		taskId, err := strconv.ParseInt(ctx.Args().First(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Error, invalid task id %q: %w", ctx.Args().First(), err)
+		}
+
+		parent, err := tasks.GetRootTask(db, taskId)
+		if err != nil {
+			return fmt.Errorf("Error getting task %d: %w", taskId, err)
+		}
+
+		segment, err := tasks.NewSegment(parent, time.Now())
+		if err != nil {
+			return err
+		}
+
+		var review io.Reader
+		if !ctx.Bool("no-review") && app.IsTerminal(os.Stdin) {
+			review = os.Stdin
+		}
+
+		var b blocker.Blocker
+		if segment.BlockerEnabled == 1 {
+			syncBlocklists()
+			b, err = blocker.New(blocker.Options{
+				Groups: ctx.StringSlice("groups"),
+				Expiry: app.LeaseExpiry(segment),
+				Strict: segment.Strict == 1,
+			})
+		} else {
+			b, err = blocker.NewNoopBlocker(blocker.Options{})
+		}
+		if err != nil {
+			return err
+		}
+
+		if segment.IsStopwatch() {
+			fmt.Printf("Resuming %s (task %d).\n", segment.TaskName, parent.TaskId)
+		} else {
+			fmt.Printf("Resuming %s (task %d), %s left.\n", segment.TaskName, parent.TaskId, time.Duration(segment.EstimatedDurationSeconds.Int64)*time.Second)
+		}
+
+		return app.Start(os.Stdout, db, segment, b, review)
+	},
+}
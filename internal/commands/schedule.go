@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/schedule"
+	"github.com/urfave/cli/v2"
+)
+
+var ScheduleCmd = &cli.Command{
+	Name:  "schedule",
+	Usage: "Manage the recurring windows blocked by `block daemon`.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List schedules and whether they are active now.",
+			Action: func(ctx *cli.Context) error {
+				rules, err := schedule.ParseRules(config.GetSchedules())
+				if err != nil {
+					return err
+				}
+
+				if len(rules) == 0 {
+					fmt.Println("No schedules configured.")
+					return nil
+				}
+
+				now := time.Now()
+				for _, rule := range rules {
+					groups := strings.Join(rule.Groups, ",")
+					if groups == "" {
+						groups = "default groups"
+					}
+
+					var active string
+					if w, ok := rule.ActiveWindow(now); ok {
+						active = fmt.Sprintf(" (active until %s)", w.End.Format(schedule.ClockFormat))
+					}
+
+					fmt.Printf("%s: %s %s-%s, blocks %s%s\n", rule.Name, rule.DaysString(), rule.Start, rule.End, groups, active)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name:      "add",
+			Usage:     "Add a schedule, e.g. block schedule add work --days weekdays --start 09:00 --end 12:00 --groups social.",
+			ArgsUsage: "[name]",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:     "days",
+					Aliases:  []string{"d"},
+					Usage:    "Days the window applies to: mon..sun, weekdays, weekends or daily.",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "start",
+					Usage:    "Start time, hh:mm.",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "end",
+					Usage:    "End time, hh:mm. An end before the start runs past midnight.",
+					Required: true,
+				},
+				&cli.StringSliceFlag{
+					Name:    "groups",
+					Aliases: []string{"g"},
+					Usage:   "Block groups to apply (defaults to defaultGroups in config).",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a schedule name")
+				}
+
+				s := config.Schedule{
+					Name:   ctx.Args().First(),
+					Days:   ctx.StringSlice("days"),
+					Start:  ctx.String("start"),
+					End:    ctx.String("end"),
+					Groups: ctx.StringSlice("groups"),
+				}
+
+				groups := config.GetBlockGroups()
+				for _, group := range s.Groups {
+					if _, ok := groups[group]; !ok {
+						return fmt.Errorf("Error, unknown group %s", group)
+					}
+				}
+
+				schedules := append([]config.Schedule(nil), config.GetSchedules()...)
+				schedules = append(schedules, s)
+
+				// validates the new schedule and rejects a duplicate name.
+				if _, err := schedule.ParseRules(schedules); err != nil {
+					return err
+				}
+
+				if err := config.SetSchedules(schedules); err != nil {
+					return err
+				}
+
+				fmt.Printf("Added schedule %s.\n", s.Name)
+				return nil
+			},
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove a schedule.",
+			ArgsUsage: "[name]",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() < 1 {
+					return errors.New("Error, expected a schedule name")
+				}
+
+				name := ctx.Args().First()
+
+				var kept []config.Schedule
+				for _, s := range config.GetSchedules() {
+					if s.Name != name {
+						kept = append(kept, s)
+					}
+				}
+
+				if len(kept) == len(config.GetSchedules()) {
+					return fmt.Errorf("Error, unknown schedule %s", name)
+				}
+
+				if err := config.SetSchedules(kept); err != nil {
+					return err
+				}
+
+				fmt.Printf("Removed schedule %s.\n", name)
+				return nil
+			},
+		},
+	},
+}
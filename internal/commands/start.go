@@ -3,13 +3,16 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/connorkuljis/block-cli/internal/app"
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/presets"
 	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/timeparse"
 	"github.com/connorkuljis/block-cli/internal/utils"
 	"github.com/jmoiron/sqlx"
 	"github.com/urfave/cli/v2"
@@ -19,7 +22,7 @@ var StartCmd = &cli.Command{
 	Name:      "start",
 	Usage:     "start the blocker.",
 	Args:      true,
-	ArgsUsage: "[duration] [taskname]",
+	ArgsUsage: "[duration | until <time> | @preset] [taskname...], or [taskname...] with --stopwatch or --until",
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "no-blocker",
@@ -30,42 +33,163 @@ var StartCmd = &cli.Command{
 			Aliases: []string{"c"},
 			Usage:   "Enables screen capture.",
 		},
-		&cli.Int64Flag{
+		&cli.StringSliceFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Block groups to apply, e.g. social,news (defaults to defaultGroups in config).",
+		},
+		&cli.StringFlag{
+			Name:    "until",
+			Aliases: []string{"u"},
+			Usage:   "End at a time of day, e.g. 17:30 or 5:30pm, instead of after a duration.",
+		},
+		&cli.BoolFlag{
+			Name:    "stopwatch",
+			Aliases: []string{"s"},
+			Usage:   "Count up without an estimate until stopped with esc or block stop.",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Pausing or quitting needs a typed confirmation and block down is refused until the session ends.",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tag",
+			Aliases: []string{"t"},
+			Usage:   "Tag the task, repeat or comma separate for several, e.g. --tag deep-work --tag api",
+		},
+		&cli.StringFlag{
+			Name:    "note",
+			Aliases: []string{"n"},
+			Usage:   "Outcome note, instead of being asked when the session ends.",
+		},
+		&cli.IntFlag{
+			Name:  "rating",
+			Usage: "Focus rating from 1 to 5, instead of being asked when the session ends.",
+		},
+		&cli.BoolFlag{
+			Name:  "no-review",
+			Usage: "Don't ask for an outcome note and rating when the session ends.",
+		},
+		&cli.StringFlag{
 			Name:    "bucket",
 			Aliases: []string{"b"},
-			Usage:   "Tag a task with bucket id",
+			Usage:   "Tag the task with a bucket, by name or id.",
 		},
 	},
 	Action: func(ctx *cli.Context) error {
 		db := ctx.Context.Value("db").(*sqlx.DB)
 		// sqlx.DB
 
-		if ctx.NArg() < 1 {
-			return errors.New("Error, no arguments provided")
+		now := time.Now()
+		argv := ctx.Args().Slice()
+
+		// a leading @name starts from a saved preset, which flags given on
+		// the command line override.
+		preset := &presets.Preset{BlockerEnabled: 1}
+		fromPreset := false
+		if len(argv) > 0 {
+			if name, ok := presets.ParseRef(argv[0]); ok {
+				saved, err := presets.GetPresetByName(db, name)
+				if err != nil {
+					return err
+				}
+				preset = &saved
+				fromPreset = true
+				argv = argv[1:]
+			}
 		}
 
-		argDurationMinutes := ctx.Args().Get(0)
-		argTaskName := ctx.Args().Get(1) // empty string is ok.
+		stopwatch := ctx.Bool("stopwatch") || (preset.Stopwatch == 1 && !ctx.IsSet("until"))
 
-		var floatDurationMinutes float64
-		floatDurationMinutes, err := strconv.ParseFloat(argDurationMinutes, 64)
+		// the task name is every remaining argument, empty is ok.
+		var args timeparse.StartArgs
+		var err error
+		switch {
+		case ctx.Bool("stopwatch") && ctx.IsSet("until"):
+			return errors.New("Error, a stopwatch can't have an end time")
+		case stopwatch:
+			args.Name = timeparse.ParseName(argv)
+		case ctx.IsSet("until"):
+			args.Duration, err = timeparse.ParseUntil(ctx.String("until"), now)
+			args.Name = timeparse.ParseName(argv)
+		case fromPreset:
+			// a preset's duration can be overridden like block start's.
+			args, err = timeparse.ParsePresetArgs(argv, preset.Duration(), now)
+		default:
+			args, err = timeparse.ParseStartArgs(argv, now)
+		}
 		if err != nil {
 			return err
 		}
 
-		durationSeconds := int64(floatDurationMinutes * 60)
+		if args.Name == "" {
+			args.Name = preset.TaskName
+		}
+
+		argTaskName := args.Name
+		durationSeconds := int64(args.Duration.Seconds())
+
+		capture := presetBool(ctx, "capture", preset.ScreenEnabled)
+		blockerEnabled := preset.BlockerEnabled == 1
+		if ctx.IsSet("no-blocker") {
+			blockerEnabled = !ctx.Bool("no-blocker")
+		}
+		bucketId, err := resolveBucketFlag(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !ctx.IsSet("bucket") && preset.BucketId.Valid {
+			bucketId = preset.BucketId.Int64
+		}
+		strict := presetBool(ctx, "strict", preset.Strict)
+		groups := ctx.StringSlice("groups")
+		if !ctx.IsSet("groups") {
+			groups = preset.Groups()
+		}
 
-		capture := ctx.Bool("capture")
-		blocker := !ctx.Bool("no-blocker")
-		bucketId := ctx.Int64("bucket")
+		if strict && !blockerEnabled {
+			return errors.New("Error, --strict needs the blocker enabled")
+		}
 
-		currentTask := tasks.NewTask(argTaskName, durationSeconds, blocker, capture, time.Now())
+		currentTask := tasks.NewTask(argTaskName, durationSeconds, blockerEnabled, capture, now)
+		if stopwatch {
+			currentTask = tasks.NewStopwatchTask(argTaskName, blockerEnabled, capture, now)
+		}
 
 		if bucketId != 0 {
 			currentTask.AddBucketTag(bucketId)
 		}
 
-		err = app.Start(os.Stdout, db, *currentTask)
+		if strict {
+			currentTask.SetStrict()
+		}
+
+		currentTask.Tags = tasks.NormaliseTags(append(preset.TagList(), ctx.StringSlice("tag")...))
+		if err := currentTask.SetOutcome(ctx.String("note"), ctx.Int("rating")); err != nil {
+			return err
+		}
+
+		var review io.Reader
+		if !ctx.Bool("no-review") && app.IsTerminal(os.Stdin) {
+			review = os.Stdin
+		}
+
+		var b blocker.Blocker
+		if blockerEnabled {
+			syncBlocklists()
+			b, err = blocker.New(blocker.Options{
+				Groups: groups,
+				Expiry: app.LeaseExpiry(currentTask),
+				Strict: strict,
+			})
+		} else {
+			b, err = blocker.NewNoopBlocker(blocker.Options{})
+		}
+		if err != nil {
+			return err
+		}
+
+		err = app.Start(os.Stdout, db, currentTask, b, review)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -91,3 +215,11 @@ var StartCmd = &cli.Command{
 		return nil
 	},
 }
+
+// presetBool is a bool flag if it was given, or else the preset's value.
+func presetBool(ctx *cli.Context, flag string, presetValue int) bool {
+	if ctx.IsSet(flag) {
+		return ctx.Bool(flag)
+	}
+	return presetValue == 1
+}
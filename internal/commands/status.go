@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/control"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var StatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Show the running session.",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "watch",
+			Aliases: []string{"w"},
+			Usage:   "Print the status every second until the session ends.",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print the status as JSON, e.g. for status bars.",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		path := config.GetSessionSocketPath()
+
+		show := func(status control.Status) bool {
+			if ctx.Bool("json") {
+				json.NewEncoder(os.Stdout).Encode(status)
+				return true
+			}
+
+			fmt.Println(formatStatus(status))
+			return true
+		}
+
+		if ctx.Bool("watch") {
+			return control.Ticks(path, show)
+		}
+
+		resp, err := control.Send(path, control.Request{Command: control.CommandStatus})
+		if err != nil {
+			return err
+		}
+
+		show(*resp.Status)
+		return nil
+	},
+}
+
+// sendControl sends a command to the running session and prints its status.
+func sendControl(req control.Request) error {
+	resp, err := control.Send(config.GetSessionSocketPath(), req)
+	if err != nil {
+		return fmt.Errorf("Error sending %s: %w", req.Command, err)
+	}
+
+	fmt.Println(formatStatus(*resp.Status))
+	return nil
+}
+
+func formatStatus(status control.Status) string {
+	name := status.TaskName
+	if name == "" {
+		name = "untitled"
+	}
+
+	var strict string
+	if status.Strict {
+		strict = ", strict"
+	}
+
+	if status.Stopwatch {
+		return fmt.Sprintf("%s: %s (task %d, stopwatch%s), %s elapsed",
+			status.State,
+			name,
+			status.TaskId,
+			strict,
+			utils.SecsToHHMMSS(status.ElapsedSeconds),
+		)
+	}
+
+	return fmt.Sprintf("%s: %s (task %d%s), %s elapsed, %s remaining",
+		status.State,
+		name,
+		status.TaskId,
+		strict,
+		utils.SecsToHHMMSS(status.ElapsedSeconds),
+		utils.SecsToHHMMSS(int64(status.Remaining().Seconds())),
+	)
+}
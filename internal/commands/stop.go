@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"github.com/connorkuljis/block-cli/internal/control"
+	"github.com/urfave/cli/v2"
+)
+
+var StopCmd = &cli.Command{
+	Name:  "stop",
+	Usage: "Cancel the running session.",
+	Action: func(ctx *cli.Context) error {
+		return sendControl(control.Request{Command: control.CommandCancel})
+	},
+}
@@ -11,9 +11,23 @@ import (
 var UpCmd = &cli.Command{
 	Name:  "up",
 	Usage: "enable the blocker",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Block groups to apply, e.g. social,news (defaults to defaultGroups in config).",
+		},
+	},
 	Action: func(ctx *cli.Context) error {
 		slog.Info("Blocker up.")
-		blocker := blocker.NewBlocker()
+		syncBlocklists()
+		blocker, err := blocker.New(blocker.Options{
+			Groups:   ctx.StringSlice("groups"),
+			Detached: true,
+		})
+		if err != nil {
+			return fmt.Errorf("Error running up command: %w", err)
+		}
 		n, err := blocker.Start()
 		if err != nil {
 			return fmt.Errorf("Error running up command: %w", err)
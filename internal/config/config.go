@@ -15,7 +15,7 @@ type AppConfig struct {
 var Cfg AppConfig
 
 func InitConfig() error {
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := resolveHomeDir(os.Geteuid(), os.Getenv("SUDO_USER"))
 	if err != nil {
 		return err
 	}
@@ -25,25 +25,54 @@ func InitConfig() error {
 		RootConfig:   NewRootConfig(homeDir),
 	}
 
-	if err := makeDirIfNotExists(Cfg.HiddenConfig.Path); err != nil {
-		return err
+	for _, dir := range []string{Cfg.HiddenConfig.Path, Cfg.RootConfig.Path} {
+		if err := makeDirIfNotExists(dir); err != nil {
+			return err
+		}
 	}
 
-	if err := makeDirIfNotExists(Cfg.RootConfig.Path); err != nil {
+	if err := loadOrMakeConfigFileIfNotExists(Cfg.HiddenConfig); err != nil {
 		return err
 	}
 
-	if err := loadOrMakeConfigFileIfNotExists(Cfg.HiddenConfig); err != nil {
+	return nil
+}
+
+// ReloadConfig re-reads the config file, picking up changes saved by other
+// processes since InitConfig.
+func ReloadConfig() error {
+	h := *Cfg.HiddenConfig
+	h.Config = NewHiddenConfig("").Config
+
+	if err := loadConfig(&h); err != nil {
 		return err
 	}
 
+	Cfg.HiddenConfig.Config = h.Config
 	return nil
 }
 
+// makeDirIfNotExists creates path and any missing parents, owned by the user
+// that ran block through sudo if it was.
 func makeDirIfNotExists(path string) error {
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) || dir == filepath.Dir(dir) {
+			break
+		}
+		missing = append(missing, dir)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, dir := range missing {
+		if err := ChownToUser(dir); err != nil {
 			return err
 		}
 	}
@@ -54,7 +83,8 @@ func makeDirIfNotExists(path string) error {
 func loadOrMakeConfigFileIfNotExists(h *HiddenConfig) error {
 	_, err := os.Stat(filepath.Join(h.Path, h.ConfigFilename))
 	if os.IsNotExist(err) {
-		if err = makeDefaultConfigFile(h); err != nil {
+		h.Config.BlockGroups = DefaultBlockGroups
+		if err = saveConfig(h); err != nil {
 			return err
 		}
 		return nil
@@ -64,6 +94,14 @@ func loadOrMakeConfigFileIfNotExists(h *HiddenConfig) error {
 			return err
 		}
 
+		// config files from before block groups start with the defaults.
+		if h.Config.BlockGroups == nil {
+			h.Config.BlockGroups = DefaultBlockGroups
+			if err := saveConfig(h); err != nil {
+				return err
+			}
+		}
+
 		err = sanitiseConfigValues(h)
 		if err != nil {
 			return err
@@ -73,8 +111,8 @@ func loadOrMakeConfigFileIfNotExists(h *HiddenConfig) error {
 	return nil
 }
 
-// create config file and write default values
-func makeDefaultConfigFile(h *HiddenConfig) error {
+// create or truncate the config file and write the current values
+func saveConfig(h *HiddenConfig) error {
 	configFile, err := os.Create(filepath.Join(h.Path, h.ConfigFilename))
 	if err != nil {
 		return err
@@ -91,6 +129,10 @@ func makeDefaultConfigFile(h *HiddenConfig) error {
 		return err
 	}
 
+	if err := ChownToUser(configFile.Name()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
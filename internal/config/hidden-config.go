@@ -11,8 +11,38 @@ type HiddenConfig struct {
 
 // represents a config file the hidden config folder
 type Config struct {
-	FfmpegRecordingsPath string `yaml:"ffmpegRecordingsPath"`
-	AvfoundationDevice   string `yaml:"avfoundationDevice"`
+	FfmpegRecordingsPath string              `yaml:"ffmpegRecordingsPath"`
+	AvfoundationDevice   string              `yaml:"avfoundationDevice"`
+	BlockGroups          map[string][]string `yaml:"blockGroups"`
+	DefaultGroups        []string            `yaml:"defaultGroups"`
+	BlockerBackend       string              `yaml:"blocker"`
+	DNS                  DNSConfig           `yaml:"dns"`
+	BlocklistSources     []BlocklistSource   `yaml:"blocklistSources"`
+	Schedules            []Schedule          `yaml:"schedules"`
+	LegacyHostsImported  bool                `yaml:"legacyHostsImported,omitempty"`
+}
+
+// a recurring window during which the daemon blocks the given groups
+type Schedule struct {
+	Name   string   `yaml:"name"`
+	Days   []string `yaml:"days"`
+	Start  string   `yaml:"start"`
+	End    string   `yaml:"end"`
+	Groups []string `yaml:"groups"`
+}
+
+// a local blocklist file re-imported into a block group on each block start
+type BlocklistSource struct {
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+	Group  string `yaml:"group"`
+}
+
+// configures the local DNS sinkhole used by the dns blocker backend
+type DNSConfig struct {
+	Listen   string `yaml:"listen"`
+	Upstream string `yaml:"upstream"`
+	Response string `yaml:"response"`
 }
 
 const (
@@ -21,12 +51,43 @@ const (
 
 	DefaultFfmpegRecordingsPath = "."
 	DefaultAvfoundationDevice   = "1:0"
+	DefaultBlockerBackend       = "hosts"
+	DefaultDNSListen            = "127.0.0.1:53"
+	DefaultDNSUpstream          = "1.1.1.1:53"
+	DefaultDNSResponse          = "nxdomain"
 )
 
+// DefaultBlockGroups is written to new config files as a starting blocklist.
+var DefaultBlockGroups = map[string][]string{
+	"social": {
+		"twitter.com",
+		"x.com",
+		"www.instagram.com",
+		"www.facebook.com",
+		"reddit.com",
+		"www.reddit.com",
+		"old.reddit.com",
+		"www.old.reddit.com",
+	},
+	"video": {
+		"youtube.com",
+		"www.youtube.com",
+	},
+	"news": {
+		"news.ycombinator.com",
+	},
+}
+
 func NewHiddenConfig(homeDir string) *HiddenConfig {
 	config := Config{
 		FfmpegRecordingsPath: DefaultFfmpegRecordingsPath,
 		AvfoundationDevice:   DefaultAvfoundationDevice,
+		BlockerBackend:       DefaultBlockerBackend,
+		DNS: DNSConfig{
+			Listen:   DefaultDNSListen,
+			Upstream: DefaultDNSUpstream,
+			Response: DefaultDNSResponse,
+		},
 	}
 
 	return &HiddenConfig{
@@ -43,3 +104,59 @@ func GetFfmpegRecordingPath() string {
 func GetAvfoundationDevice() string {
 	return Cfg.HiddenConfig.Config.AvfoundationDevice
 }
+
+func GetBlockGroups() map[string][]string {
+	return Cfg.HiddenConfig.Config.BlockGroups
+}
+
+func GetDefaultGroups() []string {
+	return Cfg.HiddenConfig.Config.DefaultGroups
+}
+
+// SetBlockGroups replaces the configured block groups and saves the config file.
+func SetBlockGroups(groups map[string][]string) error {
+	Cfg.HiddenConfig.Config.BlockGroups = groups
+	return saveConfig(Cfg.HiddenConfig)
+}
+
+func GetBlockerBackend() string {
+	return Cfg.HiddenConfig.Config.BlockerBackend
+}
+
+func GetDNSConfig() DNSConfig {
+	return Cfg.HiddenConfig.Config.DNS
+}
+
+func GetBlocklistSources() []BlocklistSource {
+	return Cfg.HiddenConfig.Config.BlocklistSources
+}
+
+// SetBlocklistSources replaces the configured blocklist sources and saves the config file.
+func SetBlocklistSources(sources []BlocklistSource) error {
+	Cfg.HiddenConfig.Config.BlocklistSources = sources
+	return saveConfig(Cfg.HiddenConfig)
+}
+
+// LegacyHostsImported reports whether the blocklist kept in the hosts file by
+// earlier versions has been imported into a block group.
+func LegacyHostsImported() bool {
+	return Cfg.HiddenConfig.Config.LegacyHostsImported
+}
+
+// SetLegacyHostsImported replaces the block groups with groups and records
+// that the legacy blocklist was imported, saving the config file.
+func SetLegacyHostsImported(groups map[string][]string) error {
+	Cfg.HiddenConfig.Config.BlockGroups = groups
+	Cfg.HiddenConfig.Config.LegacyHostsImported = true
+	return saveConfig(Cfg.HiddenConfig)
+}
+
+func GetSchedules() []Schedule {
+	return Cfg.HiddenConfig.Config.Schedules
+}
+
+// SetSchedules replaces the configured schedules and saves the config file.
+func SetSchedules(schedules []Schedule) error {
+	Cfg.HiddenConfig.Config.Schedules = schedules
+	return saveConfig(Cfg.HiddenConfig)
+}
@@ -11,7 +11,10 @@ type RootConfig struct {
 
 const (
 	RootConfigDirName = ".block-cli"
-	DbName            = "app_data.db?_time_format=sqlite"
+	DbName            = "app_data.db?_time_format=sqlite&_pragma=foreign_keys(1)"
+	LeaseFileName     = "blocker-lease.json"
+	BackupsDirName    = "backups"
+	SessionSocketName = "session.sock"
 )
 
 func NewRootConfig(homeDir string) *RootConfig {
@@ -24,3 +27,15 @@ func NewRootConfig(homeDir string) *RootConfig {
 func GetDBPath() string {
 	return filepath.Join(Cfg.RootConfig.Path, Cfg.RootConfig.DbFileName)
 }
+
+func GetLeasePath() string {
+	return filepath.Join(Cfg.RootConfig.Path, LeaseFileName)
+}
+
+func GetBackupsPath() string {
+	return filepath.Join(Cfg.RootConfig.Path, BackupsDirName)
+}
+
+func GetSessionSocketPath() string {
+	return filepath.Join(Cfg.RootConfig.Path, SessionSocketName)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// SudoUser returns the user that ran block through sudo. Their home directory
+// holds the config, database, lease and control socket, so a session started
+// with sudo can be seen and controlled without it.
+func SudoUser() (*user.User, bool) {
+	return lookupSudoUser(os.Geteuid(), os.Getenv("SUDO_USER"))
+}
+
+func lookupSudoUser(euid int, name string) (*user.User, bool) {
+	if euid != 0 || name == "" || name == "root" {
+		return nil, false
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, false
+	}
+
+	return u, true
+}
+
+// resolveHomeDir is the sudo user's home directory, or the current user's.
+func resolveHomeDir(euid int, sudoUser string) (string, error) {
+	if u, ok := lookupSudoUser(euid, sudoUser); ok {
+		return u.HomeDir, nil
+	}
+	return os.UserHomeDir()
+}
+
+// ChownToUser gives path to the user that ran block through sudo, so files
+// created as root in their home stay usable without sudo. It does nothing
+// otherwise.
+func ChownToUser(path string) error {
+	u, ok := SudoUser()
+	if !ok {
+		return nil
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return err
+	}
+
+	return os.Chown(path, uid, gid)
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"os/user"
+	"testing"
+)
+
+func TestResolveHomeDir(t *testing.T) {
+	// any account other than root stands in for the user running sudo.
+	other, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no nobody user to run sudo as")
+	}
+	own, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		euid     int
+		sudoUser string
+		want     string
+	}{
+		{name: "Without sudo", euid: 1000, want: own},
+		{name: "Sudo", euid: 0, sudoUser: other.Username, want: other.HomeDir},
+		{name: "Sudo user not root", euid: 1000, sudoUser: other.Username, want: own},
+		{name: "Root shell", euid: 0, sudoUser: "root", want: own},
+		{name: "Unknown sudo user", euid: 0, sudoUser: "no-such-block-user", want: own},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveHomeDir(tc.euid, tc.sudoUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
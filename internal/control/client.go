@@ -0,0 +1,79 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+var ErrNoSession = errors.New("Error, no session is running")
+
+const dialTimeout = 2 * time.Second
+
+// Send makes a single request to the session socket at path.
+func Send(path string, req Request) (Response, error) {
+	var resp Response
+
+	conn, err := dial(path)
+	if err != nil {
+		return resp, err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("Error reading response: %w", err)
+	}
+
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+
+	return resp, nil
+}
+
+// Ticks streams the session status to fn every second until the session
+// ends, the connection closes or fn returns false.
+func Ticks(path string, fn func(Status) bool) error {
+	conn, err := dial(path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(Request{Command: CommandTicks}); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		if resp.Status == nil || !fn(*resp.Status) || resp.Status.Done() {
+			return nil
+		}
+	}
+}
+
+func dial(path string) (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, ErrNoSession
+	}
+	return conn, err
+}
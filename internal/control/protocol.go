@@ -0,0 +1,68 @@
+package control
+
+import "time"
+
+// Commands understood by the session socket. Every request is a single line
+// of JSON and gets one JSON response line, except ticks which streams a
+// status line every second until the session ends or the client hangs up.
+const (
+	CommandStatus = "status"
+	CommandPause  = "pause"
+	CommandResume = "resume"
+	CommandExtend = "extend"
+	CommandCancel = "cancel"
+	CommandTicks  = "ticks"
+)
+
+const (
+	StateRunning   = "running"
+	StatePaused    = "paused"
+	StateFinished  = "finished"
+	StateCancelled = "cancelled"
+)
+
+type Request struct {
+	Command string `json:"command"`
+	Seconds int64  `json:"seconds,omitempty"` // for extend, may be negative
+}
+
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status is a snapshot of the running session.
+type Status struct {
+	TaskId           int64     `json:"taskId"`
+	TaskName         string    `json:"taskName"`
+	State            string    `json:"state"`
+	Strict           bool      `json:"strict"`
+	Stopwatch        bool      `json:"stopwatch,omitempty"`
+	StartedAt        time.Time `json:"startedAt"`
+	ElapsedSeconds   int64     `json:"elapsedSeconds"`
+	EstimatedSeconds int64     `json:"estimatedSeconds"`
+}
+
+// Done reports whether the session has ended.
+func (s Status) Done() bool {
+	return s.State == StateFinished || s.State == StateCancelled
+}
+
+// Remaining is the time left on the estimate, zero for a stopwatch.
+func (s Status) Remaining() time.Duration {
+	remaining := time.Duration(s.EstimatedSeconds-s.ElapsedSeconds) * time.Second
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Controller is implemented by the running session.
+type Controller interface {
+	Status() Status
+	Pause() error
+	Resume() error
+	Extend(d time.Duration) error
+	Cancel() error
+}
@@ -0,0 +1,197 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+// TickInterval is how often ticks are streamed to subscribers.
+const TickInterval = time.Second
+
+var ErrSessionRunning = errors.New("Error, another session is already listening on the control socket")
+
+// Server exposes a Controller on a Unix domain socket.
+type Server struct {
+	Path       string
+	Controller Controller
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+}
+
+// Listen creates the socket, replacing a stale one left by a crashed session.
+func (s *Server) Listen() error {
+	if conn, err := net.Dial("unix", s.Path); err == nil {
+		conn.Close()
+		return ErrSessionRunning
+	}
+
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	listener, err := net.Listen("unix", s.Path)
+	if err != nil {
+		return fmt.Errorf("Error listening on %s: %w", s.Path, err)
+	}
+
+	if err := os.Chmod(s.Path, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	if err := config.ChownToUser(s.Path); err != nil {
+		listener.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.conns = make(map[net.Conn]bool)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Serve accepts connections until Close is called.
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// Close stops accepting connections, hangs up on clients and removes the
+// socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	err := s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	if rmErr := os.Remove(s.Path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (s *Server) handle(conn net.Conn) {
+	sc := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+
+	for sc.Scan() {
+		var req Request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			enc.Encode(Response{Error: "invalid request: " + err.Error()})
+			continue
+		}
+
+		if req.Command == CommandTicks {
+			s.streamTicks(enc)
+			return
+		}
+
+		if err := enc.Encode(s.dispatch(req)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(req Request) Response {
+	var err error
+
+	switch req.Command {
+	case CommandStatus:
+	case CommandPause:
+		err = s.Controller.Pause()
+	case CommandResume:
+		err = s.Controller.Resume()
+	case CommandExtend:
+		err = s.Controller.Extend(time.Duration(req.Seconds) * time.Second)
+	case CommandCancel:
+		err = s.Controller.Cancel()
+	default:
+		err = fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	status := s.Controller.Status()
+	if err != nil {
+		return Response{Error: err.Error(), Status: &status}
+	}
+
+	return Response{OK: true, Status: &status}
+}
+
+func (s *Server) streamTicks(enc *json.Encoder) {
+	ticker := time.NewTicker(TickInterval)
+	defer ticker.Stop()
+
+	for {
+		status := s.Controller.Status()
+		if err := enc.Encode(Response{OK: true, Status: &status}); err != nil {
+			return
+		}
+
+		if status.Done() {
+			return
+		}
+
+		<-ticker.C
+	}
+}
+
+// ListenAndServe creates the socket and serves it in the background. Errors
+// after the socket is up are logged, since the session carries on without it.
+func (s *Server) ListenAndServe() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.Serve(); err != nil {
+			slog.Warn("Control socket stopped.", "error", err)
+		}
+	}()
+
+	return nil
+}
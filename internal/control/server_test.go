@@ -0,0 +1,139 @@
+package control
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeController struct {
+	mu     sync.Mutex
+	status Status
+}
+
+func (c *fakeController) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+func (c *fakeController) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State == StatePaused {
+		return errors.New("session is already paused")
+	}
+	c.status.State = StatePaused
+	return nil
+}
+
+func (c *fakeController) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.State = StateRunning
+	return nil
+}
+
+func (c *fakeController) Extend(d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.EstimatedSeconds += int64(d.Seconds())
+	return nil
+}
+
+func (c *fakeController) Cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.State = StateCancelled
+	return nil
+}
+
+func startServer(t *testing.T) (string, *fakeController) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "session.sock")
+	c := &fakeController{status: Status{TaskId: 7, State: StateRunning, EstimatedSeconds: 60}}
+	s := &Server{Path: path, Controller: c}
+	if err := s.ListenAndServe(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return path, c
+}
+
+func TestServerCommands(t *testing.T) {
+	path, _ := startServer(t)
+
+	testCases := []struct {
+		name     string
+		req      Request
+		wantErr  bool
+		state    string
+		estimate int64
+	}{
+		{name: "Status", req: Request{Command: CommandStatus}, state: StateRunning, estimate: 60},
+		{name: "Pause", req: Request{Command: CommandPause}, state: StatePaused, estimate: 60},
+		{name: "Pause twice", req: Request{Command: CommandPause}, wantErr: true, state: StatePaused, estimate: 60},
+		{name: "Resume", req: Request{Command: CommandResume}, state: StateRunning, estimate: 60},
+		{name: "Extend", req: Request{Command: CommandExtend, Seconds: 300}, state: StateRunning, estimate: 360},
+		{name: "Unknown", req: Request{Command: "explode"}, wantErr: true, state: StateRunning, estimate: 360},
+		{name: "Cancel", req: Request{Command: CommandCancel}, state: StateCancelled, estimate: 360},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := Send(path, tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+
+			if resp.Status == nil {
+				t.Fatal("Expected a status")
+			}
+			if resp.Status.State != tc.state || resp.Status.EstimatedSeconds != tc.estimate {
+				t.Errorf("Expected: %s %d, got: %s %d", tc.state, tc.estimate, resp.Status.State, resp.Status.EstimatedSeconds)
+			}
+		})
+	}
+}
+
+func TestTicksEndWithSession(t *testing.T) {
+	path, c := startServer(t)
+
+	go func() {
+		time.Sleep(TickInterval / 2)
+		c.Cancel()
+	}()
+
+	var states []string
+	err := Ticks(path, func(status Status) bool {
+		states = append(states, status.State)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(states) != 2 || states[1] != StateCancelled {
+		t.Errorf("Expected: [running cancelled], got: %v", states)
+	}
+}
+
+func TestNoSession(t *testing.T) {
+	_, err := Send(filepath.Join(t.TempDir(), "session.sock"), Request{Command: CommandStatus})
+	if !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected: %v, got: %v", ErrNoSession, err)
+	}
+}
+
+func TestListenRefusesRunningSession(t *testing.T) {
+	path, c := startServer(t)
+
+	s := &Server{Path: path, Controller: c}
+	if err := s.Listen(); !errors.Is(err, ErrSessionRunning) {
+		t.Errorf("Expected: %v, got: %v", ErrSessionRunning, err)
+	}
+}
@@ -2,11 +2,12 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/connorkuljis/block-cli/internal/buckets"
 	"github.com/connorkuljis/block-cli/internal/config"
-	"github.com/connorkuljis/block-cli/internal/tasks"
 	"github.com/jmoiron/sqlx"
+
+	_ "modernc.org/sqlite"
 )
 
 func InitDB() (*sqlx.DB, error) {
@@ -15,14 +16,14 @@ func InitDB() (*sqlx.DB, error) {
 		return nil, err
 	}
 
-	_, err = db.Exec(buckets.BucketsSchema)
+	_, err = Migrate(db)
 	if err != nil {
 		return nil, fmt.Errorf("Error initalising db schema: %w", err)
 	}
 
-	_, err = db.Exec(tasks.TasksSchema)
-	if err != nil {
-		return nil, fmt.Errorf("Error initalising db schema: %w", err)
+	path, _, _ := strings.Cut(config.GetDBPath(), "?")
+	if err := config.ChownToUser(path); err != nil {
+		return nil, err
 	}
 
 	return db, nil
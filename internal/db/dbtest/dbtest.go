@@ -0,0 +1,30 @@
+// Package dbtest opens migrated databases for tests.
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/connorkuljis/block-cli/internal/db"
+	"github.com/jmoiron/sqlx"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open returns an in-memory database with every migration applied, closed
+// when the test ends.
+func Open(t testing.TB) *sqlx.DB {
+	t.Helper()
+
+	sqlDb, err := sqlx.Connect("sqlite", "file::memory:?_time_format=sqlite&_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDb.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDb.Close() })
+
+	if _, err := db.Migrate(sqlDb); err != nil {
+		t.Fatal(err)
+	}
+
+	return sqlDb
+}
@@ -0,0 +1,389 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const MigrationsSchema = `
+	CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+      version    INTEGER PRIMARY KEY
+    , name       TEXT NOT NULL
+    , applied_at TIMESTAMP NOT NULL
+	);
+`
+
+// NoForeignKeysDirective on the first line of an up migration runs both
+// directions with foreign key enforcement off, which SQLite needs to rebuild
+// a table that others reference. Violations are checked before committing.
+const NoForeignKeysDirective = "-- migrate:foreign_keys=off"
+
+// Migration is a single versioned schema change loaded from the embedded
+// migrations directory. Files are named <version>_<name>.up.sql and,
+// optionally, <version>_<name>.down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+
+	NoForeignKeys bool
+}
+
+// MigrationStatus reports whether a known migration has been applied.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// legacyColumns lists nullable columns that databases created before
+// migrations were tracked may be missing, keyed by table name.
+var legacyColumns = map[string][][2]string{
+	"Tasks": {
+		{"status", "TEXT"},
+		{"bucket_id", "INTEGER REFERENCES Buckets(bucket_id)"},
+	},
+}
+
+// LoadMigrations reads the embedded migrations ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(filename, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(filename, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("Error loading migration %s: expected .up.sql or .down.sql suffix", filename)
+		}
+
+		base := strings.TrimSuffix(filename, "."+direction+".sql")
+		strVersion, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("Error loading migration %s: expected <version>_<name>", filename)
+		}
+
+		version, err := strconv.Atoi(strVersion)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading migration %s: %w", filename, err)
+		}
+
+		contents, err := fs.ReadFile(migrationsFS, path.Join("migrations", filename))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+
+		if m.Name != name {
+			return nil, fmt.Errorf("Error loading migration %s: version %d is already used by %s", filename, version, m.Name)
+		}
+
+		if direction == "up" {
+			m.Up = string(contents)
+			m.NoForeignKeys = strings.HasPrefix(m.Up, NoForeignKeysDirective)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	var migrations []Migration
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("Error loading migration %d_%s: missing up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrate applies every pending migration in version order. Each migration
+// runs in its own transaction together with its schema_migrations record.
+func Migrate(db *sqlx.DB) ([]Migration, error) {
+	var applied []Migration
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return applied, err
+	}
+
+	done, err := getAppliedMigrations(db)
+	if err != nil {
+		return applied, err
+	}
+
+	if len(migrations) > 0 {
+		latest := migrations[len(migrations)-1].Version
+		for version := range done {
+			if version > latest {
+				return applied, fmt.Errorf("Error migrating db: schema version %d is newer than this binary supports (%d)", version, latest)
+			}
+		}
+	}
+
+	for _, m := range migrations {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+
+		err := m.inTx(db, func(tx *sqlx.Tx) error {
+			if _, err := tx.Exec(m.Up); err != nil {
+				return err
+			}
+
+			if m.Version == 1 {
+				if err := adoptLegacySchema(tx); err != nil {
+					return err
+				}
+			}
+
+			_, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", m.Version, m.Name, time.Now())
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("Error applying migration %d_%s: %w", m.Version, m.Name, err)
+		}
+
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// Rollback reverts the most recently applied migration.
+func Rollback(db *sqlx.DB) (Migration, error) {
+	var m Migration
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return m, err
+	}
+
+	done, err := getAppliedMigrations(db)
+	if err != nil {
+		return m, err
+	}
+
+	found := false
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if _, ok := done[migrations[i].Version]; ok {
+			m = migrations[i]
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return m, errors.New("Error rolling back: no migrations have been applied")
+	}
+
+	if m.Down == "" {
+		return m, fmt.Errorf("Error rolling back: migration %d_%s cannot be reverted", m.Version, m.Name)
+	}
+
+	err = m.inTx(db, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(m.Down); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+		return err
+	})
+	if err != nil {
+		return m, fmt.Errorf("Error reverting migration %d_%s: %w", m.Version, m.Name, err)
+	}
+
+	return m, nil
+}
+
+// GetMigrationStatus lists every known migration and whether it is applied.
+func GetMigrationStatus(db *sqlx.DB) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return statuses, err
+	}
+
+	done, err := getAppliedMigrations(db)
+	if err != nil {
+		return statuses, err
+	}
+
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if a, ok := done[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func getAppliedMigrations(db *sqlx.DB) (map[int]appliedMigration, error) {
+	done := make(map[int]appliedMigration)
+
+	_, err := db.Exec(MigrationsSchema)
+	if err != nil {
+		return done, fmt.Errorf("Error initalising db schema: %w", err)
+	}
+
+	var rows []appliedMigration
+	err = db.Select(&rows, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return done, err
+	}
+
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+
+	return done, nil
+}
+
+// adoptLegacySchema adds columns that hand-run scripts may have left out of
+// databases created before migrations were tracked.
+func adoptLegacySchema(tx *sqlx.Tx) error {
+	for table, columns := range legacyColumns {
+		var existing []string
+		err := tx.Select(&existing, "SELECT name FROM pragma_table_info(?)", table)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range columns {
+			if contains(existing, column[0]) {
+				continue
+			}
+
+			_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column[0], column[1]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func inTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inTx runs fn in a transaction, with foreign keys off if the migration asks
+// for it.
+func (m Migration) inTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	if !m.NoForeignKeys {
+		return inTx(db, fn)
+	}
+
+	return inTxWithoutForeignKeys(db, fn)
+}
+
+// inTxWithoutForeignKeys runs fn on a single connection with foreign key
+// enforcement off, since the pragma has no effect inside a transaction, and
+// refuses to commit if fn left any references dangling.
+func inTxWithoutForeignKeys(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	ctx := context.Background()
+
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var enabled int
+	if err := conn.GetContext(ctx, &enabled, "PRAGMA foreign_keys"); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, fmt.Sprintf("PRAGMA foreign_keys = %d", enabled))
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	violated := rows.Next()
+	rows.Close()
+
+	if violated {
+		tx.Rollback()
+		return errors.New("foreign key check failed")
+	}
+
+	return tx.Commit()
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func openTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Connect("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateFreshDB(t *testing.T) {
+	db := openTestDB(t)
+
+	applied, err := Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d applied migrations, got: %d", len(migrations), len(applied))
+	}
+
+	applied, err = Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations on second run, got: %d", len(applied))
+	}
+}
+
+func TestMigrateAdoptsLegacySchema(t *testing.T) {
+	db := openTestDB(t)
+
+	// Tasks as left behind by the old hand-run buckets-and-status script, where a
+	// missing comma swallowed the status column.
+	legacy := `
+	CREATE TABLE Tasks (
+		task_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_name TEXT NOT NULL,
+		estimated_duration_seconds INTEGER NOT NULL,
+		actual_duration_seconds INTEGER,
+		blocker_enabled INTEGER DEFAULT 0,
+		screen_enabled INTEGER DEFAULT 0,
+		screen_url TEXT,
+		created_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP,
+		completed INTEGER,
+		completion_percent REAL
+	);
+	INSERT INTO Tasks (task_name, estimated_duration_seconds, created_at) VALUES ('legacy', 60, CURRENT_TIMESTAMP);
+	`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var columns []string
+	if err := db.Select(&columns, "SELECT name FROM pragma_table_info('Tasks')"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"status", "bucket_id"} {
+		if !contains(columns, want) {
+			t.Errorf("Expected column %s after migrating legacy db, got: %v", want, columns)
+		}
+	}
+
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM Tasks"); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Errorf("Expected legacy rows to be kept, got: %d", count)
+	}
+}
+
+func TestRollbackBaselineRefused(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		m, err := Rollback(db)
+		if err != nil {
+			if m.Version != 1 {
+				t.Fatalf("Expected rollback to stop at the baseline, stopped at %d: %v", m.Version, err)
+			}
+			break
+		}
+	}
+}
+
+func TestMigrateRebuildKeepsReferences(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	seed := `
+	INSERT INTO Tasks (task_name, estimated_duration_seconds, created_at) VALUES ('timer', 60, CURRENT_TIMESTAMP);
+	INSERT INTO Tasks (task_name, created_at) VALUES ('stopwatch', CURRENT_TIMESTAMP);
+	INSERT INTO TaskEvents (task_id, event_type, created_at) VALUES (1, 'start', CURRENT_TIMESTAMP);
+	`
+	if _, err := db.Exec(seed); err != nil {
+		t.Fatal(err)
+	}
+
+	// rolling back the stopwatch rebuild and reapplying it must leave the
+	// events pointing at their tasks.
+	for {
+		m, err := Rollback(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Version == 4 {
+			if !m.NoForeignKeys {
+				t.Errorf("Expected %d_%s to run without foreign keys", m.Version, m.Name)
+			}
+			break
+		}
+	}
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var estimate int64
+	if err := db.Get(&estimate, "SELECT estimated_duration_seconds FROM Tasks WHERE task_name = 'stopwatch'"); err != nil {
+		t.Fatal(err)
+	}
+	if estimate != 0 {
+		t.Errorf("Expected: %v, got: %v", 0, estimate)
+	}
+
+	var events int
+	if err := db.Get(&events, "SELECT COUNT(*) FROM TaskEvents WHERE task_id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if events != 1 {
+		t.Errorf("Expected: %v, got: %v", 1, events)
+	}
+
+	var enabled int
+	if err := db.Get(&enabled, "PRAGMA foreign_keys"); err != nil {
+		t.Fatal(err)
+	}
+	if enabled != 1 {
+		t.Errorf("Expected foreign keys to be restored, got: %v", enabled)
+	}
+}
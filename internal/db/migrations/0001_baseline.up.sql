@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS Buckets
+(
+  bucket_id   INTEGER PRIMARY KEY AUTOINCREMENT
+, bucket_name string
+);
+
+CREATE TABLE IF NOT EXISTS Tasks
+(
+  task_id                    INTEGER PRIMARY KEY AUTOINCREMENT
+, task_name                  TEXT NOT NULL
+, estimated_duration_seconds INTEGER NOT NULL
+, actual_duration_seconds    INTEGER
+, blocker_enabled            INTEGER DEFAULT 0
+, screen_enabled             INTEGER DEFAULT 0
+, screen_url                 TEXT
+, created_at                 TIMESTAMP NOT NULL
+, finished_at                TIMESTAMP
+, completed                  INTEGER
+, completion_percent         REAL
+, status                     TEXT
+, bucket_id                  INTEGER
+, FOREIGN KEY (bucket_id) REFERENCES Buckets(bucket_id)
+);
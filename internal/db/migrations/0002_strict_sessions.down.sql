@@ -0,0 +1,2 @@
+ALTER TABLE Tasks DROP COLUMN strict_overrides;
+ALTER TABLE Tasks DROP COLUMN strict;
@@ -0,0 +1,2 @@
+ALTER TABLE Tasks ADD COLUMN strict INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Tasks ADD COLUMN strict_overrides INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS TaskEvents
+(
+  event_id        INTEGER PRIMARY KEY AUTOINCREMENT
+, task_id         INTEGER NOT NULL
+, event_type      TEXT NOT NULL
+, created_at      TIMESTAMP NOT NULL
+, elapsed_seconds INTEGER NOT NULL DEFAULT 0
+, delta_seconds   INTEGER NOT NULL DEFAULT 0
+, FOREIGN KEY (task_id) REFERENCES Tasks(task_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON TaskEvents(task_id);
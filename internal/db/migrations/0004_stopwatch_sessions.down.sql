@@ -0,0 +1,29 @@
+-- stopwatch sessions fall back to their actual duration as the estimate.
+CREATE TABLE Tasks_old
+(
+  task_id                    INTEGER PRIMARY KEY AUTOINCREMENT
+, task_name                  TEXT NOT NULL
+, estimated_duration_seconds INTEGER NOT NULL
+, actual_duration_seconds    INTEGER
+, blocker_enabled            INTEGER DEFAULT 0
+, screen_enabled             INTEGER DEFAULT 0
+, screen_url                 TEXT
+, created_at                 TIMESTAMP NOT NULL
+, finished_at                TIMESTAMP
+, completed                  INTEGER
+, completion_percent         REAL
+, status                     TEXT
+, bucket_id                  INTEGER
+, strict                     INTEGER NOT NULL DEFAULT 0
+, strict_overrides           INTEGER NOT NULL DEFAULT 0
+, FOREIGN KEY (bucket_id) REFERENCES Buckets(bucket_id)
+);
+
+INSERT INTO Tasks_old
+(task_id, task_name, estimated_duration_seconds, actual_duration_seconds, blocker_enabled, screen_enabled, screen_url, created_at, finished_at, completed, completion_percent, status, bucket_id, strict, strict_overrides)
+SELECT
+ task_id, task_name, COALESCE(estimated_duration_seconds, actual_duration_seconds, 0), actual_duration_seconds, blocker_enabled, screen_enabled, screen_url, created_at, finished_at, completed, completion_percent, status, bucket_id, strict, strict_overrides
+FROM Tasks;
+
+DROP TABLE Tasks;
+ALTER TABLE Tasks_old RENAME TO Tasks;
@@ -0,0 +1,15 @@
+-- migrate:foreign_keys=off
+CREATE TABLE IF NOT EXISTS Cycles
+(
+  cycle_id           INTEGER PRIMARY KEY AUTOINCREMENT
+, cycle_name         TEXT NOT NULL
+, created_at         TIMESTAMP NOT NULL
+, work_seconds       INTEGER NOT NULL
+, break_seconds      INTEGER NOT NULL
+, long_break_seconds INTEGER NOT NULL
+, rounds             INTEGER NOT NULL
+);
+
+ALTER TABLE Tasks ADD COLUMN cycle_id INTEGER REFERENCES Cycles(cycle_id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_cycle_id ON Tasks(cycle_id);
@@ -0,0 +1,4 @@
+ALTER TABLE Tasks ADD COLUMN original_estimate_seconds INTEGER;
+
+-- adjustments weren't possible before, so the estimate is the original.
+UPDATE Tasks SET original_estimate_seconds = estimated_duration_seconds;
@@ -0,0 +1,6 @@
+ALTER TABLE Tasks DROP COLUMN rating;
+ALTER TABLE Tasks DROP COLUMN note;
+
+DROP INDEX IF EXISTS idx_task_tags_tag_id;
+DROP TABLE IF EXISTS TaskTags;
+DROP TABLE IF EXISTS Tags;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS Tags
+(
+  tag_id   INTEGER PRIMARY KEY AUTOINCREMENT
+, tag_name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS TaskTags
+(
+  task_id INTEGER NOT NULL
+, tag_id  INTEGER NOT NULL
+, PRIMARY KEY (task_id, tag_id)
+, FOREIGN KEY (task_id) REFERENCES Tasks(task_id) ON DELETE CASCADE
+, FOREIGN KEY (tag_id) REFERENCES Tags(tag_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id ON TaskTags(tag_id);
+
+ALTER TABLE Tasks ADD COLUMN note TEXT;
+ALTER TABLE Tasks ADD COLUMN rating INTEGER CHECK (rating BETWEEN 1 AND 5);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_buckets_bucket_name;
+ALTER TABLE Buckets DROP COLUMN archived_at;
@@ -0,0 +1,9 @@
+ALTER TABLE Buckets ADD COLUMN archived_at TIMESTAMP;
+
+-- buckets are looked up by name, so names must be present and unique.
+UPDATE Buckets SET bucket_name = 'bucket ' || bucket_id WHERE bucket_name IS NULL OR TRIM(bucket_name) = '';
+
+UPDATE Buckets SET bucket_name = bucket_name || ' (' || bucket_id || ')'
+WHERE bucket_id NOT IN (SELECT MIN(bucket_id) FROM Buckets GROUP BY bucket_name);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_buckets_bucket_name ON Buckets(bucket_name);
@@ -0,0 +1,2 @@
+ALTER TABLE Buckets DROP COLUMN budget_period;
+ALTER TABLE Buckets DROP COLUMN budget_seconds;
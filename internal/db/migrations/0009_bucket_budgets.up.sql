@@ -0,0 +1,2 @@
+ALTER TABLE Buckets ADD COLUMN budget_seconds INTEGER;
+ALTER TABLE Buckets ADD COLUMN budget_period TEXT CHECK (budget_period IN ('day', 'week', 'month'));
@@ -0,0 +1,20 @@
+-- SQLite can't drop a column with a foreign key, so Buckets is rebuilt.
+DROP INDEX IF EXISTS idx_buckets_parent_id;
+DROP INDEX IF EXISTS idx_buckets_bucket_name;
+
+CREATE TABLE Buckets_old
+(
+  bucket_id      INTEGER PRIMARY KEY AUTOINCREMENT
+, bucket_name    string
+, archived_at    TIMESTAMP
+, budget_seconds INTEGER
+, budget_period  TEXT CHECK (budget_period IN ('day', 'week', 'month'))
+);
+
+INSERT INTO Buckets_old (bucket_id, bucket_name, archived_at, budget_seconds, budget_period)
+SELECT bucket_id, bucket_name, archived_at, budget_seconds, budget_period FROM Buckets;
+
+DROP TABLE Buckets;
+ALTER TABLE Buckets_old RENAME TO Buckets;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_buckets_bucket_name ON Buckets(bucket_name);
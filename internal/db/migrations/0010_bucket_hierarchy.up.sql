@@ -0,0 +1,5 @@
+-- migrate:foreign_keys=off
+-- existing buckets become top level buckets.
+ALTER TABLE Buckets ADD COLUMN parent_id INTEGER REFERENCES Buckets(bucket_id);
+
+CREATE INDEX IF NOT EXISTS idx_buckets_parent_id ON Buckets(parent_id);
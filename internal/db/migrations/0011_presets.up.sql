@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS Presets
+(
+  preset_id        INTEGER PRIMARY KEY AUTOINCREMENT
+, preset_name      TEXT NOT NULL UNIQUE
+, task_name        TEXT NOT NULL DEFAULT ''
+, duration_seconds INTEGER
+, stopwatch        INTEGER NOT NULL DEFAULT 0
+, blocker_enabled  INTEGER NOT NULL DEFAULT 1
+, screen_enabled   INTEGER NOT NULL DEFAULT 0
+, strict           INTEGER NOT NULL DEFAULT 0
+, block_groups     TEXT NOT NULL DEFAULT ''
+, tags             TEXT NOT NULL DEFAULT ''
+, bucket_id        INTEGER REFERENCES Buckets(bucket_id) ON DELETE SET NULL
+, created_at       TIMESTAMP NOT NULL
+);
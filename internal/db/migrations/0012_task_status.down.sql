@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_status;
+ALTER TABLE Tasks DROP COLUMN pid;
+
+UPDATE Tasks SET status = NULL WHERE status != 'planned';
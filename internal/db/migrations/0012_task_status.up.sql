@@ -0,0 +1,14 @@
+-- pid is the process running the task, so a task left running by a crash can
+-- be told apart from one still running elsewhere.
+ALTER TABLE Tasks ADD COLUMN pid INTEGER;
+
+-- status was never written before. Unfinished tasks are left running without
+-- a pid, so they are reconciled from their events at the next startup.
+UPDATE Tasks SET status = CASE
+  WHEN completed = 1 THEN 'completed'
+  WHEN finished_at IS NOT NULL THEN 'cancelled'
+  ELSE 'running'
+END
+WHERE status IS NULL OR status NOT IN ('planned');
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON Tasks(status);
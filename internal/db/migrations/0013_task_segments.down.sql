@@ -0,0 +1,41 @@
+-- SQLite can't drop a column with a foreign key, so Tasks is rebuilt.
+DROP INDEX IF EXISTS idx_tasks_parent_task_id;
+DROP INDEX IF EXISTS idx_tasks_cycle_id;
+DROP INDEX IF EXISTS idx_tasks_status;
+
+CREATE TABLE Tasks_old
+(
+  task_id                    INTEGER PRIMARY KEY AUTOINCREMENT
+, task_name                  TEXT NOT NULL
+, estimated_duration_seconds INTEGER
+, actual_duration_seconds    INTEGER
+, blocker_enabled            INTEGER DEFAULT 0
+, screen_enabled             INTEGER DEFAULT 0
+, screen_url                 TEXT
+, created_at                 TIMESTAMP NOT NULL
+, finished_at                TIMESTAMP
+, completed                  INTEGER
+, completion_percent         REAL
+, status                     TEXT
+, bucket_id                  INTEGER
+, strict                     INTEGER NOT NULL DEFAULT 0
+, strict_overrides           INTEGER NOT NULL DEFAULT 0
+, cycle_id                   INTEGER REFERENCES Cycles(cycle_id)
+, original_estimate_seconds  INTEGER
+, note                       TEXT
+, rating                     INTEGER CHECK (rating BETWEEN 1 AND 5)
+, pid                        INTEGER
+, FOREIGN KEY (bucket_id) REFERENCES Buckets(bucket_id)
+);
+
+INSERT INTO Tasks_old
+(task_id, task_name, estimated_duration_seconds, actual_duration_seconds, blocker_enabled, screen_enabled, screen_url, created_at, finished_at, completed, completion_percent, status, bucket_id, strict, strict_overrides, cycle_id, original_estimate_seconds, note, rating, pid)
+SELECT
+ task_id, task_name, estimated_duration_seconds, actual_duration_seconds, blocker_enabled, screen_enabled, screen_url, created_at, finished_at, completed, completion_percent, status, bucket_id, strict, strict_overrides, cycle_id, original_estimate_seconds, note, rating, pid
+FROM Tasks;
+
+DROP TABLE Tasks;
+ALTER TABLE Tasks_old RENAME TO Tasks;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_cycle_id ON Tasks(cycle_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON Tasks(status);
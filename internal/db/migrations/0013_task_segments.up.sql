@@ -0,0 +1,5 @@
+-- migrate:foreign_keys=off
+-- a resumed task runs again as a segment linked to the task it continues.
+ALTER TABLE Tasks ADD COLUMN parent_task_id INTEGER REFERENCES Tasks(task_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_task_id ON Tasks(parent_task_id);
@@ -0,0 +1,77 @@
+package dns
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+)
+
+// LeaseBlocklist blocks the domains recorded in the blocker lease while a live
+// session holds it with the dns backend. The lease is re-read whenever the
+// file changes.
+type LeaseBlocklist struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	lease   *blocker.Lease
+	matcher *blocker.Matcher
+}
+
+func NewLeaseBlocklist(path string) *LeaseBlocklist {
+	return &LeaseBlocklist{path: path}
+}
+
+func (l *LeaseBlocklist) Blocked(name string) bool {
+	lease, matcher := l.load()
+	if lease == nil || lease.Backend != blocker.BackendDNS || lease.Orphaned(time.Now()) {
+		return false
+	}
+
+	return matcher.Blocked(name)
+}
+
+func (l *LeaseBlocklist) load() (*blocker.Lease, *blocker.Matcher) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		l.lease = nil
+		l.matcher = nil
+		l.modTime = time.Time{}
+		return nil, nil
+	}
+	if err != nil {
+		slog.Warn("Unable to read blocker lease.", "error", err)
+		return l.lease, l.matcher
+	}
+
+	if info.ModTime().Equal(l.modTime) {
+		return l.lease, l.matcher
+	}
+
+	lease, err := blocker.ReadLease(l.path)
+	if err != nil {
+		slog.Warn("Unable to read blocker lease.", "error", err)
+		return l.lease, l.matcher
+	}
+
+	var matcher *blocker.Matcher
+	if lease != nil {
+		matcher, err = blocker.NewMatcher(lease.Domains)
+		if err != nil {
+			slog.Warn("Unable to parse blocker lease.", "error", err)
+			return l.lease, l.matcher
+		}
+	}
+
+	l.lease = lease
+	l.matcher = matcher
+	l.modTime = info.ModTime()
+	return l.lease, l.matcher
+}
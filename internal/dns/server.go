@@ -0,0 +1,211 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	ResponseNXDomain = "nxdomain"
+	ResponseZero     = "zero"
+
+	DefaultTimeout = 5 * time.Second
+
+	// sinkholeTTL is kept short so lookups recover quickly once a session ends.
+	sinkholeTTL = 10
+	maxPacket   = 65535
+)
+
+// Server is a forwarding DNS resolver that answers blocked names itself and
+// passes every other query to an upstream resolver over UDP.
+type Server struct {
+	Addr     string
+	Upstream string
+
+	// Response is either ResponseNXDomain or ResponseZero (0.0.0.0 / ::).
+	Response string
+
+	// Blocked reports whether a lower-cased name, without the trailing
+	// dot, should be sinkholed.
+	Blocked func(name string) bool
+
+	Timeout time.Duration
+
+	conn net.PacketConn
+}
+
+// ListenAndServe listens on s.Addr and serves queries until Close is called.
+func (s *Server) ListenAndServe() error {
+	conn, err := net.ListenPacket("udp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("Error starting dns server: %w", err)
+	}
+
+	return s.Serve(conn)
+}
+
+// Serve answers queries read from conn until it is closed.
+func (s *Server) Serve(conn net.PacketConn) error {
+	s.conn = conn
+
+	buf := make([]byte, maxPacket)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		query := make([]byte, n)
+		copy(query, buf[:n])
+
+		go s.handle(conn, addr, query)
+	}
+}
+
+func (s *Server) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+func (s *Server) handle(conn net.PacketConn, addr net.Addr, query []byte) {
+	resp, err := s.Resolve(query)
+	if err != nil {
+		slog.Warn("Unable to resolve dns query.", "from", addr, "error", err)
+		return
+	}
+
+	if _, err := conn.WriteTo(resp, addr); err != nil {
+		slog.Warn("Unable to write dns response.", "to", addr, "error", err)
+	}
+}
+
+// Resolve answers a raw DNS query, sinkholing blocked names and forwarding
+// the rest upstream.
+func (s *Server) Resolve(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(q.Name.String()), ".")
+	if s.Blocked != nil && s.Blocked(name) {
+		slog.Debug("Sinkholed dns query.", "name", name, "type", q.Type)
+		return s.sinkhole(header, q)
+	}
+
+	resp, err := s.forward(query)
+	if err != nil {
+		slog.Warn("Unable to forward dns query.", "name", name, "upstream", s.Upstream, "error", err)
+		return s.fail(header, q)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) sinkhole(header dnsmessage.Header, q dnsmessage.Question) ([]byte, error) {
+	respHeader := replyHeader(header)
+	if s.Response != ResponseZero {
+		respHeader.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, respHeader)
+	b.EnableCompression()
+
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+
+	if s.Response == ResponseZero {
+		if err := b.StartAnswers(); err != nil {
+			return nil, err
+		}
+
+		rh := dnsmessage.ResourceHeader{Name: q.Name, Class: q.Class, TTL: sinkholeTTL}
+		switch q.Type {
+		case dnsmessage.TypeA:
+			if err := b.AResource(rh, dnsmessage.AResource{}); err != nil {
+				return nil, err
+			}
+		case dnsmessage.TypeAAAA:
+			if err := b.AAAAResource(rh, dnsmessage.AAAAResource{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.Finish()
+}
+
+func (s *Server) fail(header dnsmessage.Header, q dnsmessage.Question) ([]byte, error) {
+	respHeader := replyHeader(header)
+	respHeader.RCode = dnsmessage.RCodeServerFailure
+
+	b := dnsmessage.NewBuilder(nil, respHeader)
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+
+	return b.Finish()
+}
+
+func (s *Server) forward(query []byte) ([]byte, error) {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	conn, err := net.DialTimeout("udp", s.Upstream, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, maxPacket)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf[:n], nil
+}
+
+func replyHeader(header dnsmessage.Header) dnsmessage.Header {
+	return dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		OpCode:             header.OpCode,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+	}
+}
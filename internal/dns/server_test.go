@@ -0,0 +1,203 @@
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var upstreamAddr = [4]byte{93, 184, 216, 34}
+
+// startFakeUpstream answers every A query with upstreamAddr.
+func startFakeUpstream(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacket)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+
+			b := dnsmessage.NewBuilder(nil, replyHeader(header))
+			b.StartQuestions()
+			b.Question(q)
+			b.StartAnswers()
+			b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: q.Class, TTL: 60}, dnsmessage.AResource{A: upstreamAddr})
+			resp, err := b.Finish()
+			if err != nil {
+				continue
+			}
+
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func startServer(t *testing.T, response string, blocked []string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matcher, err := blocker.NewMatcher(blocked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		Upstream: startFakeUpstream(t),
+		Response: response,
+		Blocked:  matcher.Blocked,
+		Timeout:  time.Second,
+	}
+
+	go s.Serve(conn)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn.LocalAddr().String()
+}
+
+func query(t *testing.T, addr string, name string) dnsmessage.Message {
+	t.Helper()
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 42, RecursionDesired: true})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET})
+	packet, err := b.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if _, err := conn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, maxPacket)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(buf[:n]); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Header.ID != 42 {
+		t.Errorf("Expected response id 42, got: %d", msg.Header.ID)
+	}
+
+	return msg
+}
+
+func TestServerNXDomain(t *testing.T) {
+	addr := startServer(t, ResponseNXDomain, []string{"*.reddit.com", "!old.reddit.com", "twitter.com"})
+
+	testCases := []struct {
+		name    string
+		query   string
+		blocked bool
+	}{
+		{name: "Exact match", query: "twitter.com.", blocked: true},
+		{name: "Exact match is not a wildcard", query: "api.twitter.com.", blocked: false},
+		{name: "Wildcard apex", query: "reddit.com.", blocked: true},
+		{name: "Wildcard subdomain", query: "www.reddit.com.", blocked: true},
+		{name: "Wildcard nested subdomain", query: "www.old.reddit.com.", blocked: true},
+		{name: "Exception", query: "old.reddit.com.", blocked: false},
+		{name: "Case insensitive", query: "WWW.Reddit.com.", blocked: true},
+		{name: "Suffix without dot", query: "notreddit.com.", blocked: false},
+		{name: "Unrelated", query: "golang.org.", blocked: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := query(t, addr, tc.query)
+
+			if tc.blocked {
+				if msg.Header.RCode != dnsmessage.RCodeNameError {
+					t.Errorf("Expected NXDOMAIN, got: %v", msg.Header.RCode)
+				}
+				return
+			}
+
+			if msg.Header.RCode != dnsmessage.RCodeSuccess || len(msg.Answers) != 1 {
+				t.Fatalf("Expected forwarded answer, got: %v with %d answers", msg.Header.RCode, len(msg.Answers))
+			}
+
+			a, ok := msg.Answers[0].Body.(*dnsmessage.AResource)
+			if !ok || a.A != upstreamAddr {
+				t.Errorf("Expected upstream address %v, got: %v", upstreamAddr, msg.Answers[0].Body)
+			}
+		})
+	}
+}
+
+func TestServerZero(t *testing.T) {
+	addr := startServer(t, ResponseZero, []string{"twitter.com"})
+
+	msg := query(t, addr, "twitter.com.")
+	if msg.Header.RCode != dnsmessage.RCodeSuccess || len(msg.Answers) != 1 {
+		t.Fatalf("Expected a single answer, got: %v with %d answers", msg.Header.RCode, len(msg.Answers))
+	}
+
+	a, ok := msg.Answers[0].Body.(*dnsmessage.AResource)
+	if !ok || a.A != [4]byte{} {
+		t.Errorf("Expected 0.0.0.0, got: %v", msg.Answers[0].Body)
+	}
+}
+
+func TestServerUpstreamFailure(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// nothing listens on the closed upstream.
+	dead, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadAddr := dead.LocalAddr().String()
+	dead.Close()
+
+	s := &Server{Upstream: deadAddr, Timeout: 200 * time.Millisecond}
+	go s.Serve(conn)
+
+	msg := query(t, conn.LocalAddr().String(), "golang.org.")
+	if msg.Header.RCode != dnsmessage.RCodeServerFailure {
+		t.Errorf("Expected SERVFAIL, got: %v", msg.Header.RCode)
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"sync"
 )
 
-func RecordScreen(inputDevice string, outputPath string, cancel chan bool, finish chan error, wg *sync.WaitGroup) error {
+// RecordScreen records until stop is closed or ffmpeg exits.
+func RecordScreen(inputDevice string, outputPath string, stop <-chan struct{}) error {
 	inputFormat := "avfoundation" // input format.
 	frameRate := "25"             // frame rate.
 	codec := "libx264"            // codec.
@@ -40,6 +40,7 @@ func RecordScreen(inputDevice string, outputPath string, cancel chan bool, finis
 	if err := cmd.Start(); err != nil {
 		return err
 	}
+	finish := make(chan error, 1)
 	go func() {
 		finish <- cmd.Wait()
 	}()
@@ -54,16 +55,14 @@ func RecordScreen(inputDevice string, outputPath string, cancel chan bool, finis
 
 	// Wait for either the stop signal or the process to finish
 	select {
-	case <-cancel:
+	case <-stop:
 		log.Println("Received stop signal, terminating FFmpeg")
 		if err := cmd.Process.Signal(os.Interrupt); err != nil {
 			log.Println("Failed to send interrupt signal:", err)
 			cmd.Process.Kill()
 		}
-		wg.Done()
 		return <-finish
 	case err := <-finish:
-		wg.Done()
 		return err
 	}
 }
@@ -1,15 +1,24 @@
 package interactive
 
 import (
+	"fmt"
 	"log"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/eiannone/keyboard"
 )
 
-func PollInput(remote *Remote) {
+// StrictPhrase must be typed to pause or cancel a strict session.
+const StrictPhrase = "i am choosing to be distracted"
+
+// ExtendStep is how much the + and - keys add to or take off the estimate.
+const ExtendStep = 5 * time.Minute
+
+// PollInput drives the session from the keyboard until it ends.
+func (remote *Remote) PollInput(events <-chan Event) {
 	err := keyboard.Open()
 	if err != nil {
 		panic(err)
@@ -22,54 +31,115 @@ func PollInput(remote *Remote) {
 		panic(err)
 	}
 
-	paused := false
+	pollKeys(remote, keysEvents, events)
+}
+
+// override collects the confirmation phrase for a key held back by strict
+// mode.
+type override struct {
+	held  keyboard.KeyEvent
+	typed []rune
+}
+
+// read handles a key press while the phrase is being typed. It reports
+// whether input is done and, if so, whether the phrase matched.
+func (o *override) read(event keyboard.KeyEvent) (done bool, confirmed bool) {
+	switch event.Key {
+	case keyboard.KeyEnter:
+		return true, strings.EqualFold(strings.TrimSpace(string(o.typed)), StrictPhrase)
+	case keyboard.KeyEsc, keyboard.KeyCtrlC:
+		return true, false
+	case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+		if len(o.typed) > 0 {
+			o.typed = o.typed[:len(o.typed)-1]
+		}
+	case keyboard.KeySpace:
+		o.typed = append(o.typed, ' ')
+	default:
+		if event.Rune != 0 {
+			o.typed = append(o.typed, event.Rune)
+		}
+	}
+	return false, false
+}
+
+// pollKeys handles key presses until the session ends. Space pauses and
+// resumes, + and - extend and shorten the estimate, esc or control-C cancels,
+// or stops a stopwatch. In strict mode, pausing, shortening and cancelling
+// wait for the confirmation phrase and each confirmed override is counted on
+// the task.
+func pollKeys(remote *Remote, keysEvents <-chan keyboard.KeyEvent, events <-chan Event) {
+	var pending *override
 	spinner := spinner.New(spinner.CharSets[40], 100*time.Millisecond)
 	spinner.Prefix = "Press any key to resume:"
+	defer spinner.Stop()
+
 	for {
 		select {
-		case <-remote.Finish:
-			remote.Wg.Done()
-			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			// the spinner follows pauses from the control socket too.
+			switch event.Type {
+			case EventPaused:
+				spinner.Start()
+			case EventResumed:
+				spinner.Stop()
+			}
 		case event := <-keysEvents:
 			if event.Err != nil {
 				panic(event.Err)
 			}
 
-			if event.Key == keyboard.KeyCtrlC || event.Key == keyboard.KeyEsc {
-				if paused {
-					spinner.Stop()
-					close(remote.Pause)
+			paused := remote.Session.State() == StatePaused
+
+			if pending != nil {
+				done, confirmed := pending.read(event)
+				if !done {
+					continue
+				}
+
+				held := pending.held
+				pending = nil
+				if !confirmed {
+					fmt.Fprintln(remote.W, "Override aborted, keep going.")
+					continue
 				}
+
+				remote.Task.StrictOverrides++
+				slog.Warn("Strict block overridden.", "overrides", remote.Task.StrictOverrides)
+				remote.recordOverride()
+				event = held
+			} else if remote.Strict && (isCancelKey(event.Key) || event.Key == keyboard.KeySpace && !paused || event.Rune == '-') {
+				pending = &override{held: event}
+				fmt.Fprintf(remote.W, "\nStrict mode: type %q and press enter to continue, or esc to go back.\n", StrictPhrase)
+				continue
+			}
+
+			var err error
+			if isCancelKey(event.Key) {
 				slog.Info("Cancelling.")
-				close(remote.Cancel)
-				remote.Wg.Done()
-				return
+				err = remote.end()
 			} else if event.Key == keyboard.KeySpace {
-				paused = !paused
 				if paused {
-					unpause(remote, spinner)
+					err = remote.Session.Resume()
 				} else {
-					pause(remote, spinner)
+					err = remote.Session.Pause()
 				}
+			} else if event.Rune == '+' || event.Rune == '=' {
+				err = remote.Session.Extend(ExtendStep)
+			} else if event.Rune == '-' {
+				err = remote.Session.Extend(-ExtendStep)
+			}
+			if err != nil {
+				log.Print(err)
 			}
 		}
 	}
 }
 
-func unpause(remote *Remote, spinner *spinner.Spinner) {
-	_, err := remote.Blocker.Stop()
-	if err != nil {
-		log.Print(err)
-	}
-	remote.Pause <- true
-	spinner.Start()
-}
-
-func pause(remote *Remote, spinner *spinner.Spinner) {
-	spinner.Stop()
-	_, err := remote.Blocker.Start()
-	if err != nil {
-		log.Print(err)
-	}
-	remote.Pause <- true
+func isCancelKey(key keyboard.Key) bool {
+	return key == keyboard.KeyCtrlC || key == keyboard.KeyEsc
 }
@@ -0,0 +1,215 @@
+package interactive
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/eiannone/keyboard"
+)
+
+// startPolling runs pollKeys against a started session. It returns the key
+// channel, a subscription for observing the session and a channel closed
+// when pollKeys returns.
+func startPolling(t *testing.T, remote *Remote) (chan keyboard.KeyEvent, <-chan Event, chan struct{}) {
+	t.Helper()
+
+	keys := make(chan keyboard.KeyEvent)
+	events := remote.Session.Subscribe()
+	observed := remote.Session.Subscribe()
+	done := make(chan struct{})
+
+	go func() {
+		pollKeys(remote, keys, events)
+		close(done)
+	}()
+
+	if err := remote.Session.Start(); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, observed, EventStarted)
+
+	return keys, observed, done
+}
+
+func expectEvent(t *testing.T, events <-chan Event, want EventType) {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		if event.Type != want {
+			t.Fatalf("Expected: %s, got: %s", want, event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected: %s, got nothing", want)
+	}
+}
+
+func newTestRemote(strict bool) *Remote {
+	return &Remote{
+		Task:    &tasks.Task{},
+		Session: NewSession(time.Minute, newFakeClock()),
+		Strict:  strict,
+		W:       io.Discard,
+	}
+}
+
+func TestPollKeysPauseResume(t *testing.T) {
+	remote := newTestRemote(false)
+	keys, observed, done := startPolling(t, remote)
+
+	keys <- keyboard.KeyEvent{Key: keyboard.KeySpace}
+	expectEvent(t, observed, EventPaused)
+
+	keys <- keyboard.KeyEvent{Key: keyboard.KeySpace}
+	expectEvent(t, observed, EventResumed)
+
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyEsc}
+	expectEvent(t, observed, EventCancelled)
+	<-done
+}
+
+func typeKeys(keys chan<- keyboard.KeyEvent, s string) {
+	for _, r := range s {
+		if r == ' ' {
+			keys <- keyboard.KeyEvent{Key: keyboard.KeySpace}
+			continue
+		}
+		keys <- keyboard.KeyEvent{Rune: r}
+	}
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyEnter}
+}
+
+func TestPollKeysStrict(t *testing.T) {
+	remote := newTestRemote(true)
+	keys, observed, done := startPolling(t, remote)
+
+	// a wrong phrase, or going back, keeps the session running.
+	keys <- keyboard.KeyEvent{Key: keyboard.KeySpace}
+	typeKeys(keys, "let me out")
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyEsc}
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyEsc}
+
+	// the phrase confirms the held back key.
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyCtrlC}
+	typeKeys(keys, StrictPhrase)
+	expectEvent(t, observed, EventCancelled)
+	<-done
+
+	if remote.Task.StrictOverrides != 1 {
+		t.Errorf("Expected: %d, got: %d", 1, remote.Task.StrictOverrides)
+	}
+}
+
+func TestPollKeysExtend(t *testing.T) {
+	remote := newTestRemote(true)
+	keys, observed, done := startPolling(t, remote)
+
+	keys <- keyboard.KeyEvent{Rune: '+'}
+	expectEvent(t, observed, EventExtended)
+
+	// shortening a strict session waits for the phrase.
+	keys <- keyboard.KeyEvent{Rune: '-'}
+	typeKeys(keys, StrictPhrase)
+	expectEvent(t, observed, EventExtended)
+
+	if estimate := remote.Session.Snapshot().Estimate; estimate != time.Minute {
+		t.Errorf("Expected: %v, got: %v", time.Minute, estimate)
+	}
+
+	keys <- keyboard.KeyEvent{Key: keyboard.KeyCtrlC}
+	typeKeys(keys, StrictPhrase)
+	expectEvent(t, observed, EventCancelled)
+	<-done
+}
+
+func TestControllerStrict(t *testing.T) {
+	remote := newTestRemote(true)
+	remote.Session.Start()
+	c := controller{remote}
+
+	if err := c.Pause(); err == nil {
+		t.Errorf("Expected pausing a strict session to fail")
+	}
+	if err := c.Extend(-time.Minute); err == nil {
+		t.Errorf("Expected shortening a strict session to fail")
+	}
+	if err := c.Cancel(); err == nil {
+		t.Errorf("Expected cancelling a strict session to fail")
+	}
+	if err := c.Extend(time.Minute); err != nil {
+		t.Errorf("Expected extending a strict session to work, got: %v", err)
+	}
+
+	if status := c.Status(); status.State != "running" || status.EstimatedSeconds != 120 {
+		t.Errorf("Expected: running 120, got: %s %d", status.State, status.EstimatedSeconds)
+	}
+}
+
+func TestFollowBlocker(t *testing.T) {
+	noop, err := blocker.NewNoopBlocker(blocker.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote := newTestRemote(false)
+	remote.Blocker = noop
+
+	events := make(chan Event, 3)
+	events <- Event{Type: EventPaused}
+	events <- Event{Type: EventResumed}
+	events <- Event{Type: EventTick}
+	close(events)
+
+	remote.FollowBlocker(events)
+
+	if starts, stops := noop.Calls(); starts != 1 || stops != 1 {
+		t.Errorf("Expected 1 start and 1 stop, got: %d, %d", starts, stops)
+	}
+}
+
+func TestFollowBlockerRenewsLease(t *testing.T) {
+	// the dns backend only writes the lease, so it runs without root.
+	home := t.TempDir()
+	config.Cfg = config.AppConfig{HiddenConfig: config.NewHiddenConfig(home), RootConfig: config.NewRootConfig(home)}
+	config.Cfg.HiddenConfig.Config.BlockGroups = map[string][]string{"social": {"reddit.com"}}
+	if err := os.MkdirAll(config.Cfg.RootConfig.Path, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := blocker.NewDNSBlocker(blocker.Options{Groups: []string{"social"}, Expiry: 10*time.Minute + blocker.LeaseGrace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	remote := newTestRemote(false)
+	remote.Blocker = b
+
+	// a 10 minute session is extended to 40 minutes a minute in.
+	events := make(chan Event, 1)
+	events <- Event{Type: EventExtended, Elapsed: time.Minute, Estimate: 40 * time.Minute}
+	close(events)
+
+	remote.FollowBlocker(events)
+
+	lease, err := blocker.ReadLease(config.GetLeasePath())
+	if err != nil || lease == nil {
+		t.Fatalf("Expected a lease, got: %v, %v", lease, err)
+	}
+
+	later := time.Now().Add(30 * time.Minute)
+	if lease.Orphaned(later) {
+		t.Errorf("Expected the extended session's lease to outlive the grace period, expires: %v", lease.ExpiresAt)
+	}
+	if !lease.Orphaned(later.Add(15 * time.Minute)) {
+		t.Errorf("Expected the lease to expire after the extended estimate, expires: %v", lease.ExpiresAt)
+	}
+}
@@ -1,10 +1,11 @@
 package interactive
 
 import (
+	"fmt"
 	"io"
-	"time"
 
 	"github.com/connorkuljis/block-cli/internal/utils"
+
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -19,37 +20,34 @@ func initProgressBar(max int, w io.Writer) *progressbar.ProgressBar {
 	)
 }
 
-func RenderProgressBar(remote *Remote) {
-	durationSeconds := int(remote.Task.EstimatedDurationSeconds)
-	pbar := initProgressBar(durationSeconds, remote.W)
-
-	ticker := time.NewTicker(time.Second * 1)
-
-	i := 0
-	paused := false
-	for {
-		select {
-		case <-remote.Cancel:
-			remote.TotalTimeSeconds <- i
-			remote.CompletionPercent <- pbar.State().CurrentPercent * 100
-			remote.Wg.Done()
-			return
-		case <-remote.Pause:
-			paused = !paused
-		case <-ticker.C:
-			if i == durationSeconds {
-				remote.TotalTimeSeconds <- i
-				remote.CompletionPercent <- pbar.State().CurrentPercent * 100
-				utils.SendNotification()
-				close(remote.Finish)
-				remote.Wg.Done()
-				return
-			}
-
-			if !paused {
-				pbar.Add(1)
-				i++
-			}
+// RenderProgressBar draws the session's elapsed time against its estimate,
+// or just the elapsed time for a stopwatch.
+func (remote *Remote) RenderProgressBar(events <-chan Event) {
+	if remote.Task.IsStopwatch() {
+		renderElapsed(remote.W, events)
+		return
+	}
+
+	pbar := initProgressBar(int(remote.Task.EstimatedDurationSeconds.Int64), remote.W)
+
+	for event := range events {
+		switch event.Type {
+		case EventExtended:
+			pbar.ChangeMax(int(event.Estimate.Seconds()))
+		case EventTick, EventFinished:
+			pbar.Set(int(event.Elapsed.Seconds()))
+		}
+	}
+}
+
+// renderElapsed redraws the elapsed time in place on every tick.
+func renderElapsed(w io.Writer, events <-chan Event) {
+	for event := range events {
+		switch event.Type {
+		case EventStarted, EventTick:
+			fmt.Fprintf(w, "\rElapsed: %s", utils.SecsToHHMMSS(int64(event.Elapsed.Seconds())))
+		case EventFinished, EventCancelled:
+			fmt.Fprintf(w, "\rElapsed: %s\n", utils.SecsToHHMMSS(int64(event.Elapsed.Seconds())))
 		}
 	}
 }
@@ -1,66 +1,281 @@
 package interactive
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/control"
 	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/utils"
 	"github.com/jmoiron/sqlx"
 )
 
+// TickInterval is how often the session is ticked and the bar redrawn.
+const TickInterval = time.Second
+
+// CheckpointInterval is how often a running session's elapsed time is saved.
+const CheckpointInterval = time.Minute
+
+// Remote ties a Session to the task it is timing and the terminal, blocker
+// and recorder following it.
 type Remote struct {
 	Task    *tasks.Task
+	Session *Session
 	Blocker blocker.Blocker
 	Db      *sqlx.DB
-
-	W                 io.Writer
-	Wg                *sync.WaitGroup
-	Pause             chan bool
-	Cancel            chan bool
-	Finish            chan error
-	CompletionPercent chan float64
-	TotalTimeSeconds  chan int
+	Strict  bool
+	W       io.Writer
 }
 
-func Run(w io.Writer, task *tasks.Task, blocker blocker.Blocker, db *sqlx.DB) (int, float64) {
-	remote := &Remote{
-		Task:              task,
-		Blocker:           blocker,
-		Db:                db,
-		Wg:                &sync.WaitGroup{},
-		W:                 w,
-		Pause:             make(chan bool, 1),
-		Cancel:            make(chan bool, 1),
-		Finish:            make(chan error, 1),
-		CompletionPercent: make(chan float64, 1),
-		TotalTimeSeconds:  make(chan int, 1),
+func Run(w io.Writer, task *tasks.Task, b blocker.Blocker, db *sqlx.DB) (int, float64) {
+	session := NewSession(time.Duration(task.EstimatedDurationSeconds.Int64)*time.Second, SystemClock)
+	if task.IsStopwatch() {
+		session = NewStopwatch(SystemClock)
 	}
 
-	remote.Wg.Add(2)
-
-	slog.Info("Rendering progress bar")
-	go RenderProgressBar(remote)
+	remote := &Remote{
+		Task:    task,
+		Session: session,
+		Blocker: b,
+		Db:      db,
+		Strict:  task.Strict == 1,
+		W:       w,
+	}
 
-	slog.Info("Polling input")
-	go PollInput(remote)
+	server := &control.Server{Path: config.GetSessionSocketPath(), Controller: controller{remote}}
+	if err := server.ListenAndServe(); err != nil {
+		slog.Warn("Control socket unavailable, block status won't see this session.", "error", err)
+	} else {
+		defer server.Close()
+	}
 
+	followers := []follower{
+		{"progress bar", remote.RenderProgressBar},
+		{"keyboard", remote.PollInput},
+		{"blocker", remote.FollowBlocker},
+		{"notifier", notifyOnFinish},
+		{"event log", remote.RecordEvents},
+	}
 	if task.ScreenEnabled == 1 {
-		remote.Wg.Add(1)
-		slog.Info("Capturing screen.")
-		go FfmpegCaptureScreen(remote)
+		followers = append(followers, follower{"screen recorder", remote.FfmpegCaptureScreen})
 	}
 
 	fmt.Println("---")
-	fmt.Println("Press [q] or [esc] or [control-C] to quit.")
+	if task.IsStopwatch() {
+		fmt.Println("Press [esc] or [control-C] to stop the stopwatch.")
+	} else {
+		fmt.Println("Press [q] or [esc] or [control-C] to quit.")
+		fmt.Printf("Press [+] or [-] to add or take off %s.\n", ExtendStep)
+	}
 	fmt.Println("Press [space] key to pause (re-enables sites temporarily).")
+	if remote.Strict {
+		fmt.Println("Strict mode: pausing or quitting asks you to type a confirmation phrase.")
+	}
+
+	remote.run(followers...)
+
+	snapshot := remote.Session.Snapshot()
+	if !snapshot.Stopwatch {
+		task.EstimatedDurationSeconds.Int64 = int64(snapshot.Estimate.Seconds())
+	}
+	return int(snapshot.Elapsed.Seconds()), remote.Session.CompletionPercent()
+}
+
+// RunBreak times a break between focus sessions. Nothing is blocked or
+// recorded, and esc skips the rest of the break.
+func RunBreak(w io.Writer, name string, d time.Duration) {
+	remote := &Remote{
+		Task:    tasks.NewTask(name, int64(d.Seconds()), false, false, time.Now()),
+		Session: NewSession(d, SystemClock),
+		W:       w,
+	}
+
+	fmt.Println("---")
+	fmt.Println("Press [esc] or [control-C] to skip the break.")
+	fmt.Println("Press [space] key to pause.")
+	fmt.Printf("Press [+] or [-] to add or take off %s.\n", ExtendStep)
+
+	remote.run(
+		follower{"progress bar", remote.RenderProgressBar},
+		follower{"keyboard", remote.PollInput},
+	)
+}
+
+type follower struct {
+	name string
+	fn   func(events <-chan Event)
+}
+
+// run starts the session and returns once every follower has seen it end.
+// Followers subscribe before the session starts so none miss an event.
+func (remote *Remote) run(followers ...follower) {
+	var wg sync.WaitGroup
+	for _, f := range followers {
+		events := remote.Session.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slog.Info("Following session.", "follower", f.name)
+			f.fn(events)
+		}()
+	}
+
+	if err := remote.Session.Start(); err != nil {
+		log.Print(err)
+	}
+	go remote.Session.RunTicker(TickInterval)
+
+	wg.Wait()
+}
+
+// FollowBlocker lifts the block while the session is paused, and keeps the
+// lease expiry in step with the time left as the session is extended.
+func (remote *Remote) FollowBlocker(events <-chan Event) {
+	for event := range events {
+		var err error
+		switch event.Type {
+		case EventPaused:
+			_, err = remote.Blocker.Stop()
+		case EventResumed:
+			if _, err = remote.Blocker.Start(); err == nil {
+				err = remote.Blocker.Renew(event.Estimate - event.Elapsed + blocker.LeaseGrace)
+			}
+		case EventExtended:
+			err = remote.Blocker.Renew(event.Estimate - event.Elapsed + blocker.LeaseGrace)
+		}
+		if err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// RecordEvents stores every session change except ticks in TaskEvents, and
+// keeps the task's estimate and status up to date. While running, a
+// checkpoint is kept every CheckpointInterval so a crash can be reconciled.
+func (remote *Remote) RecordEvents(events <-chan Event) {
+	var checkpointAt time.Time
+	for event := range events {
+		switch event.Type {
+		case EventTick:
+			if event.At.Sub(checkpointAt) >= CheckpointInterval {
+				checkpointAt = event.At
+				if err := tasks.RecordCheckpoint(remote.Db, remote.Task.TaskId, event.At, int64(event.Elapsed.Seconds())); err != nil {
+					slog.Warn("Unable to record checkpoint.", "error", err)
+				}
+			}
+			continue
+		case EventExtended:
+			if err := tasks.UpdateEstimate(remote.Db, remote.Task.TaskId, int64(event.Estimate.Seconds())); err != nil {
+				slog.Warn("Unable to update estimate.", "error", err)
+			}
+		case EventPaused, EventResumed:
+			status := tasks.StatusRunning
+			if event.Type == EventPaused {
+				status = tasks.StatusPaused
+			}
+			if err := tasks.UpdateStatus(remote.Db, remote.Task.TaskId, status); err != nil {
+				slog.Warn("Unable to update status.", "error", err)
+			}
+		}
+
+		taskEvent := &tasks.TaskEvent{
+			TaskId:         remote.Task.TaskId,
+			EventType:      string(event.Type),
+			CreatedAt:      event.At,
+			ElapsedSeconds: int64(event.Elapsed.Seconds()),
+			DeltaSeconds:   int64(event.Delta.Seconds()),
+		}
+
+		if err := tasks.InsertTaskEvent(remote.Db, taskEvent); err != nil {
+			slog.Warn("Unable to record session event.", "event", event.Type, "error", err)
+		}
+	}
+}
+
+// recordOverride flags a confirmed strict override as it happens, so it is
+// kept even if the session never finishes.
+func (remote *Remote) recordOverride() {
+	if remote.Db == nil {
+		return
+	}
+
+	taskEvent := &tasks.TaskEvent{
+		TaskId:         remote.Task.TaskId,
+		EventType:      tasks.EventOverride,
+		CreatedAt:      remote.Session.clock.Now(),
+		ElapsedSeconds: int64(remote.Session.Snapshot().Elapsed.Seconds()),
+	}
+
+	if err := tasks.InsertTaskEvent(remote.Db, taskEvent); err != nil {
+		slog.Warn("Unable to record strict override.", "error", err)
+	}
+}
+
+func notifyOnFinish(events <-chan Event) {
+	for event := range events {
+		if event.Type == EventFinished {
+			utils.SendNotification()
+		}
+	}
+}
 
-	remote.Wg.Wait()
+// controller exposes the session to the control socket. A strict session can
+// only be paused, shortened or cancelled from its own terminal.
+type controller struct {
+	remote *Remote
+}
+
+func (c controller) Status() control.Status {
+	snapshot := c.remote.Session.Snapshot()
+
+	return control.Status{
+		TaskId:           c.remote.Task.TaskId,
+		TaskName:         c.remote.Task.TaskName,
+		State:            string(snapshot.State),
+		Strict:           c.remote.Strict,
+		Stopwatch:        snapshot.Stopwatch,
+		StartedAt:        snapshot.StartedAt,
+		ElapsedSeconds:   int64(snapshot.Elapsed.Seconds()),
+		EstimatedSeconds: int64(snapshot.Estimate.Seconds()),
+	}
+}
+
+func (c controller) Pause() error {
+	if c.remote.Strict {
+		return errors.New("strict session can only be paused from its terminal")
+	}
+	return c.remote.Session.Pause()
+}
 
-	percent := <-remote.CompletionPercent
-	totalTimeSeconds := <-remote.TotalTimeSeconds
+func (c controller) Resume() error {
+	return c.remote.Session.Resume()
+}
+
+func (c controller) Extend(d time.Duration) error {
+	if c.remote.Strict && d < 0 {
+		return errors.New("strict session can't be shortened")
+	}
+	return c.remote.Session.Extend(d)
+}
 
-	return totalTimeSeconds, percent
+func (c controller) Cancel() error {
+	if c.remote.Strict {
+		return errors.New("strict session can only be cancelled from its terminal")
+	}
+	return c.remote.end()
+}
+
+// end stops a stopwatch, which is how it finishes, and cancels a countdown.
+func (remote *Remote) end() error {
+	if remote.Session.Snapshot().Stopwatch {
+		return remote.Session.Stop()
+	}
+	return remote.Session.Cancel()
 }
@@ -26,7 +26,8 @@ func conventionalFilename(timestamp, name, filetype string) string {
 	return timestamp + seperator + strings.ReplaceAll(name, " ", concatenator) + filetype
 }
 
-func FfmpegCaptureScreen(remote *Remote) {
+// FfmpegCaptureScreen records the screen until the session ends.
+func (remote *Remote) FfmpegCaptureScreen(events <-chan Event) {
 	var filename string
 
 	timestamp := remote.Task.CreatedAt.Format(TimeFormat)
@@ -41,8 +42,16 @@ func FfmpegCaptureScreen(remote *Remote) {
 	recordingPath := config.GetFfmpegRecordingPath()
 	outputFile := filepath.Join(recordingPath, filename)
 
-	ffmpeg.RecordScreen(config.GetAvfoundationDevice(), outputFile, remote.Cancel, remote.Finish, remote.Wg)
+	stop := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(stop)
+	}()
 
+	if err := ffmpeg.RecordScreen(config.GetAvfoundationDevice(), outputFile, stop); err != nil {
+		log.Print(err)
+	}
 }
 
 func terminate(cmd *exec.Cmd) {
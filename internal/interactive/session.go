@@ -0,0 +1,398 @@
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateRunning   State = "running"
+	StatePaused    State = "paused"
+	StateFinished  State = "finished"
+	StateCancelled State = "cancelled"
+)
+
+// Done reports whether the state is terminal.
+func (s State) Done() bool {
+	return s == StateFinished || s == StateCancelled
+}
+
+type EventType string
+
+const (
+	EventStarted   EventType = tasks.EventStart
+	EventPaused    EventType = tasks.EventPause
+	EventResumed   EventType = tasks.EventResume
+	EventExtended  EventType = tasks.EventExtend
+	EventTick      EventType = "tick"
+	EventFinished  EventType = tasks.EventFinish
+	EventCancelled EventType = tasks.EventCancel
+)
+
+// Event is published to subscribers on every session change.
+type Event struct {
+	Type     EventType
+	At       time.Time
+	Elapsed  time.Duration
+	Estimate time.Duration
+	Delta    time.Duration // for extend
+}
+
+// Clock tells the session the time, so tests can drive it.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock reads the wall clock.
+var SystemClock Clock = systemClock{}
+
+// Session is the timer behind a block session. It counts running time
+// against an estimate, independent of any terminal, and publishes an Event
+// for every change. Elapsed time is read from the clock rather than counted
+// in ticks, so a late tick never loses time.
+type Session struct {
+	clock Clock
+
+	mu          sync.Mutex
+	state       State
+	open        bool // a stopwatch, with no estimate
+	estimate    time.Duration
+	elapsed     time.Duration // running time up to resumedAt
+	startedAt   time.Time
+	resumedAt   time.Time
+	subscribers []*subscriber
+}
+
+func NewSession(estimate time.Duration, clock Clock) *Session {
+	return &Session{
+		clock:    clock,
+		state:    StateIdle,
+		estimate: estimate,
+	}
+}
+
+// NewStopwatch returns a session that counts up without an estimate until
+// it is stopped.
+func NewStopwatch(clock Clock) *Session {
+	return &Session{
+		clock: clock,
+		state: StateIdle,
+		open:  true,
+	}
+}
+
+// Snapshot is a consistent view of the session.
+type Snapshot struct {
+	State     State
+	StartedAt time.Time
+	Elapsed   time.Duration
+	Estimate  time.Duration
+	Stopwatch bool
+}
+
+func (s *Session) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Snapshot{
+		State:     s.state,
+		StartedAt: s.startedAt,
+		Elapsed:   s.elapsedAt(s.clock.Now()),
+		Estimate:  s.estimate,
+		Stopwatch: s.open,
+	}
+}
+
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state
+}
+
+// CompletionPercent is the share of the estimate spent running, exactly 100
+// once the session has finished. A stopwatch has no estimate and reports -1.
+func (s *Session) CompletionPercent() float64 {
+	snapshot := s.Snapshot()
+	if snapshot.Stopwatch {
+		return -1
+	}
+	if snapshot.State == StateFinished {
+		return 100
+	}
+	if snapshot.Estimate <= 0 {
+		return 0
+	}
+
+	return min(float64(snapshot.Elapsed)/float64(snapshot.Estimate)*100, 100)
+}
+
+// Subscribe returns a channel receiving every event from now on. Events are
+// queued rather than dropped, and the channel is closed after the session
+// finishes or is cancelled.
+func (s *Session) Subscribe() <-chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := newSubscriber()
+	if s.state.Done() {
+		sub.close()
+	} else {
+		s.subscribers = append(s.subscribers, sub)
+	}
+	return sub.out
+}
+
+func (s *Session) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("start", StateIdle); err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	s.state = StateRunning
+	s.startedAt = now
+	s.resumedAt = now
+	s.publish(EventStarted, now, 0)
+	return nil
+}
+
+func (s *Session) Pause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("pause", StateRunning); err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	s.elapsed = s.elapsedAt(now)
+	s.state = StatePaused
+	s.publish(EventPaused, now, 0)
+	return nil
+}
+
+func (s *Session) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("resume", StatePaused); err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	s.state = StateRunning
+	s.resumedAt = now
+	s.publish(EventResumed, now, 0)
+	return nil
+}
+
+// Extend adds d, which may be negative, to the estimate. The estimate never
+// drops below the elapsed time, so shortening too far finishes the session
+// on the next tick.
+func (s *Session) Extend(d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("extend", StateRunning, StatePaused); err != nil {
+		return err
+	}
+	if s.open {
+		return errors.New("Error, a stopwatch has no estimate to extend")
+	}
+
+	now := s.clock.Now()
+	before := s.estimate
+	s.estimate = max(s.estimate+d, s.elapsedAt(now))
+	s.publish(EventExtended, now, s.estimate-before)
+	return nil
+}
+
+func (s *Session) Cancel() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("cancel", StateRunning, StatePaused); err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	s.elapsed = s.elapsedAt(now)
+	s.state = StateCancelled
+	s.publish(EventCancelled, now, 0)
+	s.closeSubscribers()
+	return nil
+}
+
+// Stop finishes a stopwatch. Countdown sessions finish on their own and are
+// cancelled instead.
+func (s *Session) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.expect("stop", StateRunning, StatePaused); err != nil {
+		return err
+	}
+	if !s.open {
+		return errors.New("Error, only a stopwatch can be stopped")
+	}
+
+	now := s.clock.Now()
+	s.elapsed = s.elapsedAt(now)
+	s.state = StateFinished
+	s.publish(EventFinished, now, 0)
+	s.closeSubscribers()
+	return nil
+}
+
+// Tick publishes the elapsed time and finishes the session once the
+// estimate is reached. It does nothing unless the session is running.
+func (s *Session) Tick() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateRunning {
+		return
+	}
+
+	now := s.clock.Now()
+	if s.open || s.elapsedAt(now) < s.estimate {
+		s.publish(EventTick, now, 0)
+		return
+	}
+
+	s.elapsed = s.estimate
+	s.state = StateFinished
+	s.publish(EventFinished, now, 0)
+	s.closeSubscribers()
+}
+
+// RunTicker ticks the session every interval until it ends.
+func (s *Session) RunTicker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.Tick()
+		if s.State().Done() {
+			return
+		}
+	}
+}
+
+func (s *Session) expect(action string, states ...State) error {
+	for _, state := range states {
+		if s.state == state {
+			return nil
+		}
+	}
+	return fmt.Errorf("Error, can't %s a %s session", action, s.state)
+}
+
+// elapsedAt returns the running time at now. Callers hold mu.
+func (s *Session) elapsedAt(now time.Time) time.Duration {
+	if s.state != StateRunning {
+		return s.elapsed
+	}
+	return s.elapsed + now.Sub(s.resumedAt)
+}
+
+// publish queues an event for every subscriber. Callers hold mu.
+func (s *Session) publish(eventType EventType, now time.Time, delta time.Duration) {
+	event := Event{
+		Type:     eventType,
+		At:       now,
+		Elapsed:  s.elapsedAt(now),
+		Estimate: s.estimate,
+		Delta:    delta,
+	}
+
+	for _, sub := range s.subscribers {
+		sub.send(event)
+	}
+}
+
+// closeSubscribers ends every subscription. Callers hold mu.
+func (s *Session) closeSubscribers() {
+	for _, sub := range s.subscribers {
+		sub.close()
+	}
+	s.subscribers = nil
+}
+
+// subscriber buffers events without bound so publishing never blocks on a
+// slow reader, which may itself be waiting to call into the session.
+type subscriber struct {
+	mu     sync.Mutex
+	queue  []Event
+	closed bool
+	wake   chan struct{}
+	out    chan Event
+}
+
+func newSubscriber() *subscriber {
+	sub := &subscriber{
+		wake: make(chan struct{}, 1),
+		out:  make(chan Event),
+	}
+	go sub.pump()
+	return sub
+}
+
+func (sub *subscriber) send(event Event) {
+	sub.mu.Lock()
+	sub.queue = append(sub.queue, event)
+	sub.mu.Unlock()
+	sub.notify()
+}
+
+func (sub *subscriber) close() {
+	sub.mu.Lock()
+	sub.closed = true
+	sub.mu.Unlock()
+	sub.notify()
+}
+
+func (sub *subscriber) notify() {
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscriber) pump() {
+	for range sub.wake {
+		for {
+			sub.mu.Lock()
+			if len(sub.queue) == 0 {
+				closed := sub.closed
+				sub.mu.Unlock()
+				if closed {
+					close(sub.out)
+					return
+				}
+				break
+			}
+			event := sub.queue[0]
+			sub.queue = sub.queue[1:]
+			sub.mu.Unlock()
+
+			sub.out <- event
+		}
+	}
+}
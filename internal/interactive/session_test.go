@@ -0,0 +1,207 @@
+package interactive
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// collect reads events until the session ends.
+func collect(events <-chan Event) []Event {
+	var out []Event
+	for event := range events {
+		out = append(out, event)
+	}
+	return out
+}
+
+func TestSessionTransitions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		actions []func(s *Session) error
+		state   State
+		wantErr bool
+	}{
+		{name: "Pause before start", actions: []func(s *Session) error{(*Session).Pause}, state: StateIdle, wantErr: true},
+		{name: "Start", actions: []func(s *Session) error{(*Session).Start}, state: StateRunning},
+		{name: "Start twice", actions: []func(s *Session) error{(*Session).Start, (*Session).Start}, state: StateRunning, wantErr: true},
+		{name: "Pause", actions: []func(s *Session) error{(*Session).Start, (*Session).Pause}, state: StatePaused},
+		{name: "Pause twice", actions: []func(s *Session) error{(*Session).Start, (*Session).Pause, (*Session).Pause}, state: StatePaused, wantErr: true},
+		{name: "Resume running", actions: []func(s *Session) error{(*Session).Start, (*Session).Resume}, state: StateRunning, wantErr: true},
+		{name: "Cancel paused", actions: []func(s *Session) error{(*Session).Start, (*Session).Pause, (*Session).Cancel}, state: StateCancelled},
+		{name: "Resume cancelled", actions: []func(s *Session) error{(*Session).Start, (*Session).Cancel, (*Session).Resume}, state: StateCancelled, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSession(time.Minute, newFakeClock())
+
+			var err error
+			for _, action := range tc.actions {
+				err = action(s)
+			}
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if s.State() != tc.state {
+				t.Errorf("Expected: %s, got: %s", tc.state, s.State())
+			}
+		})
+	}
+}
+
+func TestSessionPausedTimeIsNotCounted(t *testing.T) {
+	clock := newFakeClock()
+	s := NewSession(time.Minute, clock)
+	events := s.Subscribe()
+
+	s.Start()
+	clock.Advance(10 * time.Second)
+	s.Pause()
+	clock.Advance(time.Hour)
+	s.Resume()
+	clock.Advance(5 * time.Second)
+	s.Tick()
+	s.Extend(-50 * time.Second)
+	clock.Advance(time.Second)
+	s.Tick()
+
+	got := collect(events)
+	want := []struct {
+		eventType EventType
+		elapsed   time.Duration
+		estimate  time.Duration
+	}{
+		{EventStarted, 0, time.Minute},
+		{EventPaused, 10 * time.Second, time.Minute},
+		{EventResumed, 10 * time.Second, time.Minute},
+		{EventTick, 15 * time.Second, time.Minute},
+		// shortening clamps the estimate to the elapsed time.
+		{EventExtended, 15 * time.Second, 15 * time.Second},
+		{EventFinished, 15 * time.Second, 15 * time.Second},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d events, got: %v", len(want), got)
+	}
+
+	for i, w := range want {
+		if got[i].Type != w.eventType || got[i].Elapsed != w.elapsed || got[i].Estimate != w.estimate {
+			t.Errorf("Expected: %s %v/%v, got: %s %v/%v", w.eventType, w.elapsed, w.estimate, got[i].Type, got[i].Elapsed, got[i].Estimate)
+		}
+	}
+
+	if percent := s.CompletionPercent(); percent != 100 {
+		t.Errorf("Expected: 100, got: %v", percent)
+	}
+}
+
+func TestSessionFinishCapsElapsed(t *testing.T) {
+	clock := newFakeClock()
+	s := NewSession(30*time.Second, clock)
+	s.Start()
+
+	// a late tick, e.g. after the machine slept.
+	clock.Advance(10 * time.Minute)
+	s.Tick()
+
+	snapshot := s.Snapshot()
+	if snapshot.State != StateFinished || snapshot.Elapsed != 30*time.Second {
+		t.Errorf("Expected: finished after 30s, got: %s after %v", snapshot.State, snapshot.Elapsed)
+	}
+
+	if err := s.Cancel(); err == nil {
+		t.Errorf("Expected cancelling a finished session to fail")
+	}
+
+	// late subscribers see the session has already ended.
+	if events := collect(s.Subscribe()); len(events) != 0 {
+		t.Errorf("Expected no events, got: %v", events)
+	}
+}
+
+func TestStopwatchRunsUntilStopped(t *testing.T) {
+	clock := newFakeClock()
+	s := NewStopwatch(clock)
+	s.Start()
+
+	clock.Advance(10 * time.Hour)
+	s.Tick()
+
+	if s.State() != StateRunning {
+		t.Errorf("Expected: %s, got: %s", StateRunning, s.State())
+	}
+
+	if err := s.Extend(time.Minute); err == nil {
+		t.Errorf("Expected extending a stopwatch to fail")
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := s.Snapshot()
+	if snapshot.State != StateFinished || snapshot.Elapsed != 10*time.Hour {
+		t.Errorf("Expected: finished after 10h, got: %s after %v", snapshot.State, snapshot.Elapsed)
+	}
+
+	if percent := s.CompletionPercent(); percent != -1 {
+		t.Errorf("Expected: -1, got: %v", percent)
+	}
+
+	countdown := NewSession(time.Minute, clock)
+	countdown.Start()
+	if err := countdown.Stop(); err == nil {
+		t.Errorf("Expected stopping a countdown to fail")
+	}
+}
+
+func TestSessionCancelFinishRace(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		clock := newFakeClock()
+		s := NewSession(time.Second, clock)
+		events := s.Subscribe()
+		s.Start()
+		clock.Advance(time.Second)
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() { defer wg.Done(); s.Tick() }()
+		go func() { defer wg.Done(); s.Cancel() }()
+		go func() { defer wg.Done(); s.Pause() }()
+		wg.Wait()
+
+		terminal := 0
+		for _, event := range collect(events) {
+			if event.Type == EventFinished || event.Type == EventCancelled {
+				terminal++
+			}
+		}
+
+		if terminal != 1 {
+			t.Fatalf("Expected exactly one terminal event, got: %d", terminal)
+		}
+	}
+}
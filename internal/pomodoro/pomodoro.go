@@ -0,0 +1,129 @@
+package pomodoro
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type PhaseKind string
+
+const (
+	PhaseFocus     PhaseKind = "focus"
+	PhaseBreak     PhaseKind = "break"
+	PhaseLongBreak PhaseKind = "long break"
+)
+
+// Phase is one timed step of a pomodoro cycle. Round counts focus phases
+// from 1; a break has the round of the focus phase before it.
+type Phase struct {
+	Kind     PhaseKind
+	Round    int
+	Duration time.Duration
+}
+
+func (p Phase) String() string {
+	return fmt.Sprintf("%s %d (%s)", p.Kind, p.Round, p.Duration)
+}
+
+// Plan describes a pomodoro cycle: Rounds focus phases with a break after
+// each one. Every LongBreakEvery rounds, and after the last, the break is the
+// long break.
+type Plan struct {
+	Work           time.Duration
+	Break          time.Duration
+	LongBreak      time.Duration
+	LongBreakEvery int
+	Rounds         int
+}
+
+func (p Plan) Validate() error {
+	if p.Work <= 0 {
+		return errors.New("Error, work duration must be positive")
+	}
+	if p.Break < 0 || p.LongBreak < 0 {
+		return errors.New("Error, break durations must not be negative")
+	}
+	if p.Rounds < 1 {
+		return errors.New("Error, need at least one cycle")
+	}
+	if p.LongBreakEvery < 0 {
+		return errors.New("Error, long break interval must not be negative")
+	}
+	return nil
+}
+
+// Phases lists the phases in order. Zero length breaks are left out.
+func (p Plan) Phases() []Phase {
+	var phases []Phase
+	for round := 1; round <= p.Rounds; round++ {
+		phases = append(phases, Phase{Kind: PhaseFocus, Round: round, Duration: p.Work})
+
+		rest := Phase{Kind: PhaseBreak, Round: round, Duration: p.Break}
+		if round == p.Rounds || (p.LongBreakEvery > 0 && round%p.LongBreakEvery == 0) {
+			rest = Phase{Kind: PhaseLongBreak, Round: round, Duration: p.LongBreak}
+		}
+		if rest.Duration > 0 {
+			phases = append(phases, rest)
+		}
+	}
+	return phases
+}
+
+// Pomodoro runs a plan's phases back to back. Focus runs a focus phase and
+// reports whether it was completed; Break runs a break, which may be cut
+// short. Notify is called as each phase begins.
+type Pomodoro struct {
+	Plan   Plan
+	Focus  func(phase Phase) (completed bool, err error)
+	Break  func(phase Phase) error
+	Notify func(message string)
+}
+
+// Run works through the plan, stopping early if a focus phase is cancelled.
+// It returns the number of completed focus phases.
+func (p *Pomodoro) Run() (int, error) {
+	if err := p.Plan.Validate(); err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, phase := range p.Plan.Phases() {
+		p.notify(phase)
+
+		if phase.Kind != PhaseFocus {
+			if err := p.Break(phase); err != nil {
+				return completed, err
+			}
+			continue
+		}
+
+		ok, err := p.Focus(phase)
+		if err != nil {
+			return completed, err
+		}
+		if !ok {
+			return completed, nil
+		}
+		completed++
+	}
+
+	if p.Notify != nil {
+		p.Notify(fmt.Sprintf("Pomodoro done, %d of %d cycles completed.", completed, p.Plan.Rounds))
+	}
+
+	return completed, nil
+}
+
+func (p *Pomodoro) notify(phase Phase) {
+	if p.Notify == nil {
+		return
+	}
+
+	switch phase.Kind {
+	case PhaseFocus:
+		p.Notify(fmt.Sprintf("Focus %d of %d: %s, sites blocked.", phase.Round, p.Plan.Rounds, phase.Duration))
+	default:
+		p.Notify(fmt.Sprintf("Take a %s: %s, sites unblocked.", phase.Kind, phase.Duration))
+	}
+}
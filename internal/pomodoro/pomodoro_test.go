@@ -0,0 +1,125 @@
+package pomodoro
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanPhases(t *testing.T) {
+	testCases := []struct {
+		name string
+		plan Plan
+		want []PhaseKind
+	}{
+		{
+			name: "Classic",
+			plan: Plan{Work: 25 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, Rounds: 3},
+			want: []PhaseKind{PhaseFocus, PhaseBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseLongBreak},
+		},
+		{
+			name: "Long break every two",
+			plan: Plan{Work: 25 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 2, Rounds: 5},
+			want: []PhaseKind{PhaseFocus, PhaseBreak, PhaseFocus, PhaseLongBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseLongBreak, PhaseFocus, PhaseLongBreak},
+		},
+		{
+			name: "Long break every four",
+			plan: Plan{Work: 25 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 4, Rounds: 8},
+			want: []PhaseKind{PhaseFocus, PhaseBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseLongBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseBreak, PhaseFocus, PhaseLongBreak},
+		},
+		{
+			name: "No long break",
+			plan: Plan{Work: 25 * time.Minute, Break: 5 * time.Minute, Rounds: 2},
+			want: []PhaseKind{PhaseFocus, PhaseBreak, PhaseFocus},
+		},
+		{
+			name: "No breaks",
+			plan: Plan{Work: 25 * time.Minute, Rounds: 2},
+			want: []PhaseKind{PhaseFocus, PhaseFocus},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			phases := tc.plan.Phases()
+
+			var got []PhaseKind
+			for _, phase := range phases {
+				got = append(got, phase.Kind)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expected: %v, got: %v", tc.want, got)
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected: %v, got: %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+	valid := Plan{Work: 25 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 4, Rounds: 4}
+
+	testCases := []struct {
+		name    string
+		edit    func(p *Plan)
+		wantErr bool
+	}{
+		{name: "Valid", edit: func(p *Plan) {}},
+		{name: "No breaks", edit: func(p *Plan) { p.Break, p.LongBreak, p.LongBreakEvery = 0, 0, 0 }},
+		{name: "No work", edit: func(p *Plan) { p.Work = 0 }, wantErr: true},
+		{name: "Negative break", edit: func(p *Plan) { p.Break = -time.Minute }, wantErr: true},
+		{name: "Negative interval", edit: func(p *Plan) { p.LongBreakEvery = -1 }, wantErr: true},
+		{name: "No rounds", edit: func(p *Plan) { p.Rounds = 0 }, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := valid
+			tc.edit(&plan)
+			if err := plan.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPomodoroStopsOnCancelledFocus(t *testing.T) {
+	var ran []Phase
+	var notes []string
+
+	p := &Pomodoro{
+		Plan: Plan{Work: time.Minute, Break: time.Minute, LongBreak: time.Minute, Rounds: 4},
+		Focus: func(phase Phase) (bool, error) {
+			ran = append(ran, phase)
+			// the second focus phase is cancelled.
+			return phase.Round != 2, nil
+		},
+		Break: func(phase Phase) error {
+			ran = append(ran, phase)
+			return nil
+		},
+		Notify: func(message string) {
+			notes = append(notes, message)
+		},
+	}
+
+	completed, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if completed != 1 {
+		t.Errorf("Expected: %v, got: %v", 1, completed)
+	}
+
+	if len(ran) != 3 {
+		t.Errorf("Expected focus, break, focus, got: %v", ran)
+	}
+
+	if len(notes) != 3 {
+		t.Errorf("Expected a notification per phase, got: %v", notes)
+	}
+}
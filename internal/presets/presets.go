@@ -0,0 +1,242 @@
+package presets
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// Prefix marks a preset in place of a duration, e.g. block start @standup-prep.
+const Prefix = "@"
+
+// Preset is a saved set of block start options for a recurring session.
+type Preset struct {
+	PresetId        int64         `db:"preset_id"`
+	PresetName      string        `db:"preset_name"`
+	TaskName        string        `db:"task_name"`
+	DurationSeconds sql.NullInt64 `db:"duration_seconds"`
+	Stopwatch       int           `db:"stopwatch"`
+	BlockerEnabled  int           `db:"blocker_enabled"`
+	ScreenEnabled   int           `db:"screen_enabled"`
+	Strict          int           `db:"strict"`
+	BlockGroups     string        `db:"block_groups"` // comma separated
+	Tags            string        `db:"tags"`         // comma separated
+	BucketId        sql.NullInt64 `db:"bucket_id"`
+	CreatedAt       time.Time     `db:"created_at"`
+
+	// BucketName is joined in when reading presets.
+	BucketName sql.NullString `db:"bucket_name"`
+}
+
+// ParseRef returns the preset name in an argument like @standup-prep.
+func ParseRef(arg string) (string, bool) {
+	name, found := strings.CutPrefix(arg, Prefix)
+	return name, found && name != ""
+}
+
+// ValidName trims a preset name and an optional leading @.
+func ValidName(name string) (string, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), Prefix)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return name, fmt.Errorf("Error, invalid preset name %q, expected a single word like standup-prep", name)
+	}
+	return name, nil
+}
+
+// FromTask copies the options a past task was started with. Block groups
+// aren't recorded on tasks, so the preset uses the default groups.
+func FromTask(name string, task tasks.Task) Preset {
+	return Preset{
+		PresetName:      name,
+		TaskName:        task.TaskName,
+		DurationSeconds: task.OriginalEstimateSeconds,
+		Stopwatch:       utils.BoolToInt(task.IsStopwatch()),
+		BlockerEnabled:  task.BlockerEnabled,
+		ScreenEnabled:   task.ScreenEnabled,
+		Strict:          task.Strict,
+		Tags:            strings.Join(task.Tags, ","),
+		BucketId:        task.BucketId,
+	}
+}
+
+func (p Preset) Duration() time.Duration {
+	return time.Duration(p.DurationSeconds.Int64) * time.Second
+}
+
+func (p Preset) Groups() []string {
+	return splitList(p.BlockGroups)
+}
+
+func (p Preset) TagList() []string {
+	return splitList(p.Tags)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// Describe summarises the preset's options, e.g. 15m0s, no blocker, bucket meetings.
+func (p Preset) Describe() string {
+	var parts []string
+
+	if p.Stopwatch == 1 {
+		parts = append(parts, "stopwatch")
+	} else {
+		parts = append(parts, p.Duration().String())
+	}
+	if p.TaskName != "" {
+		parts = append(parts, fmt.Sprintf("%q", p.TaskName))
+	}
+	if p.BlockerEnabled == 0 {
+		parts = append(parts, "no blocker")
+	} else if p.BlockGroups != "" {
+		parts = append(parts, "groups "+p.BlockGroups)
+	}
+	if p.Strict == 1 {
+		parts = append(parts, "strict")
+	}
+	if p.ScreenEnabled == 1 {
+		parts = append(parts, "capture")
+	}
+	if p.BucketName.Valid {
+		parts = append(parts, "bucket "+p.BucketName.String)
+	}
+	if p.Tags != "" {
+		parts = append(parts, "tags "+p.Tags)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (p Preset) validate() error {
+	if p.Stopwatch == 0 && (!p.DurationSeconds.Valid || p.DurationSeconds.Int64 <= 0) {
+		return errors.New("Error, a preset needs a duration unless it is a stopwatch")
+	}
+	if p.Strict == 1 && p.BlockerEnabled == 0 {
+		return errors.New("Error, a strict preset needs the blocker enabled")
+	}
+	return nil
+}
+
+// SavePreset stores a preset, replacing any preset with the same name.
+func SavePreset(db *sqlx.DB, preset *Preset) error {
+	name, err := ValidName(preset.PresetName)
+	if err != nil {
+		return err
+	}
+	preset.PresetName = name
+
+	if err := preset.validate(); err != nil {
+		return err
+	}
+
+	if preset.Stopwatch == 1 {
+		preset.DurationSeconds = sql.NullInt64{Valid: false}
+	}
+	if preset.CreatedAt.IsZero() {
+		preset.CreatedAt = time.Now()
+	}
+
+	query := `INSERT INTO Presets
+	(
+	  preset_name
+	, task_name
+	, duration_seconds
+	, stopwatch
+	, blocker_enabled
+	, screen_enabled
+	, strict
+	, block_groups
+	, tags
+	, bucket_id
+	, created_at
+	)
+	VALUES
+	(
+	  :preset_name
+	, :task_name
+	, :duration_seconds
+	, :stopwatch
+	, :blocker_enabled
+	, :screen_enabled
+	, :strict
+	, :block_groups
+	, :tags
+	, :bucket_id
+	, :created_at
+	)
+	ON CONFLICT (preset_name) DO UPDATE SET
+	  task_name = excluded.task_name
+	, duration_seconds = excluded.duration_seconds
+	, stopwatch = excluded.stopwatch
+	, blocker_enabled = excluded.blocker_enabled
+	, screen_enabled = excluded.screen_enabled
+	, strict = excluded.strict
+	, block_groups = excluded.block_groups
+	, tags = excluded.tags
+	, bucket_id = excluded.bucket_id`
+
+	if _, err := db.NamedExec(query, preset); err != nil {
+		return fmt.Errorf("Error saving preset %s: %w", preset.PresetName, err)
+	}
+
+	saved, err := GetPresetByName(db, preset.PresetName)
+	if err != nil {
+		return err
+	}
+	*preset = saved
+
+	return nil
+}
+
+const selectPresets = `SELECT p.*, b.bucket_name FROM Presets p LEFT JOIN Buckets b ON b.bucket_id = p.bucket_id`
+
+func GetPresetByName(db *sqlx.DB, name string) (Preset, error) {
+	var preset Preset
+
+	err := db.Get(&preset, selectPresets+" WHERE p.preset_name = ?", strings.TrimPrefix(name, Prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return preset, fmt.Errorf("Error, no preset named %s, see block preset list", name)
+	}
+	if err != nil {
+		return preset, err
+	}
+
+	return preset, nil
+}
+
+func GetAllPresets(db *sqlx.DB) ([]Preset, error) {
+	var presets []Preset
+
+	err := db.Select(&presets, selectPresets+" ORDER BY p.preset_name")
+	if err != nil {
+		return presets, err
+	}
+
+	return presets, nil
+}
+
+func DeletePreset(db *sqlx.DB, name string) error {
+	result, err := db.Exec("DELETE FROM Presets WHERE preset_name = ?", strings.TrimPrefix(name, Prefix))
+	if err != nil {
+		return fmt.Errorf("Error deleting preset %s: %w", name, err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("Error, no preset named %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package presets
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+func TestParseRef(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{input: "@standup-prep", want: "standup-prep", ok: true},
+		{input: "@", ok: false},
+		{input: "25", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, ok := ParseRef(tc.input)
+			if ok != tc.ok || (ok && got != tc.want) {
+				t.Errorf("Expected: %v %v, got: %v %v", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestSavePreset(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	testCases := []struct {
+		name    string
+		preset  Preset
+		wantErr bool
+	}{
+		{name: "no duration", preset: Preset{PresetName: "a", BlockerEnabled: 1}, wantErr: true},
+		{name: "stopwatch", preset: Preset{PresetName: "b", Stopwatch: 1, BlockerEnabled: 1}},
+		{name: "strict without blocker", preset: Preset{PresetName: "c", DurationSeconds: seconds(60), Strict: 1}, wantErr: true},
+		{name: "spaces", preset: Preset{PresetName: "standup prep", DurationSeconds: seconds(60)}, wantErr: true},
+		{name: "leading @", preset: Preset{PresetName: "@standup", DurationSeconds: seconds(900)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SavePreset(sqlDb, &tc.preset)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	// saving again replaces the preset.
+	update := Preset{PresetName: "standup", DurationSeconds: seconds(600), Tags: "meetings"}
+	if err := SavePreset(sqlDb, &update); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := GetPresetByName(sqlDb, "@standup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Duration() != 10*time.Minute || saved.Tags != "meetings" {
+		t.Errorf("Expected: 10m0s meetings, got: %v %v", saved.Duration(), saved.Tags)
+	}
+}
+
+func TestFromTask(t *testing.T) {
+	task := tasks.NewTask("standup prep", 900, false, false, time.Now())
+	task.EstimatedDurationSeconds = seconds(1200) // extended while it ran
+	task.AddBucketTag(3)
+	task.Tags = []string{"meetings"}
+
+	p := FromTask("standup", *task)
+	if p.Duration() != 15*time.Minute {
+		t.Errorf("Expected: %v, got: %v", 15*time.Minute, p.Duration())
+	}
+	if p.BlockerEnabled != 0 || p.BucketId.Int64 != 3 || p.Tags != "meetings" || p.TaskName != "standup prep" {
+		t.Errorf("Expected the task's options, got: %+v", p)
+	}
+}
+
+func seconds(n int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: n, Valid: true}
+}
@@ -0,0 +1,249 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/jmoiron/sqlx"
+)
+
+const DefaultInterval = 30 * time.Second
+
+// Daemon applies the block for whichever schedule windows are active and
+// records each window as a task. Every tick re-evaluates the rules against
+// the wall clock, so a tick after sleep or a clock change catches up.
+type Daemon struct {
+	Db       *sqlx.DB
+	Interval time.Duration
+
+	// Rules returns the current schedule rules, so edits to config are
+	// picked up without restarting the daemon.
+	Rules func() ([]Rule, error)
+
+	// NewBlocker returns a blocker for the given groups.
+	NewBlocker func(groups []string) (blocker.Blocker, error)
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	rules   []Rule
+	open    map[string]openWindow
+	blocker blocker.Blocker
+	groups  []string
+}
+
+type openWindow struct {
+	window Window
+	task   tasks.Task
+}
+
+// Run ticks until ctx is cancelled, then closes any open windows and lifts
+// the block.
+func (d *Daemon) Run(ctx context.Context) error {
+	interval := d.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Tick(); err != nil {
+			slog.Error("Schedule tick failed.", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return d.Shutdown()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick opens and closes windows for the current time and updates the block.
+func (d *Daemon) Tick() error {
+	now := d.now()
+
+	rules, err := d.Rules()
+	if err != nil {
+		slog.Warn("Unable to load schedules, keeping the previous rules.", "error", err)
+	} else {
+		d.rules = rules
+	}
+
+	if d.open == nil {
+		d.open = make(map[string]openWindow)
+	}
+
+	active := make(map[string]Window)
+	for _, rule := range d.rules {
+		if w, ok := rule.ActiveWindow(now); ok {
+			active[rule.Name] = w
+		}
+	}
+
+	// close windows that have ended, or been replaced after a clock change.
+	for name, ow := range d.open {
+		w, ok := active[name]
+		if ok && w.Start.Equal(ow.window.Start) {
+			continue
+		}
+
+		if err := d.closeWindow(ow, now); err != nil {
+			return err
+		}
+		delete(d.open, name)
+	}
+
+	var windows []Window
+	for _, rule := range d.rules {
+		w, ok := active[rule.Name]
+		if !ok {
+			continue
+		}
+
+		if _, ok := d.open[rule.Name]; !ok {
+			ow, err := d.openWindow(w, now)
+			if err != nil {
+				return err
+			}
+			d.open[rule.Name] = ow
+		}
+
+		windows = append(windows, d.open[rule.Name].window)
+	}
+
+	return d.apply(ActiveGroups(windows))
+}
+
+// Shutdown finishes the tasks of open windows and lifts the block.
+func (d *Daemon) Shutdown() error {
+	now := d.now()
+
+	var errs []error
+	for name, ow := range d.open {
+		errs = append(errs, d.closeWindow(ow, now))
+		delete(d.open, name)
+	}
+
+	errs = append(errs, d.apply(nil))
+	return errors.Join(errs...)
+}
+
+// Blocking returns the groups currently blocked by the daemon.
+func (d *Daemon) Blocking() []string {
+	return d.groups
+}
+
+func (d *Daemon) now() time.Time {
+	if d.Now == nil {
+		return time.Now().Round(0)
+	}
+	return d.Now()
+}
+
+// openWindow records the window as a task. A task left unfinished for the
+// same window, e.g. by a restarted daemon, is picked up instead.
+func (d *Daemon) openWindow(w Window, now time.Time) (openWindow, error) {
+	task, err := tasks.GetUnfinishedTaskByName(d.Db, w.Rule.Name, w.Start)
+	if err == nil {
+		if err := tasks.ReopenTask(d.Db, &task, os.Getpid()); err != nil {
+			return openWindow{}, err
+		}
+		slog.Info("Resumed scheduled window.", "schedule", w.Rule.Name, "task", task.TaskId)
+		return openWindow{window: w, task: task}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return openWindow{}, err
+	}
+
+	estimate := int64(w.End.Sub(now).Seconds())
+	task = *tasks.NewTask(w.Rule.Name, estimate, true, false, now)
+	task.SetRunning(os.Getpid())
+	if err := tasks.InsertTask(d.Db, &task); err != nil {
+		return openWindow{}, err
+	}
+
+	slog.Info("Opened scheduled window.", "schedule", w.Rule.Name, "task", task.TaskId, "until", w.End.Format(ClockFormat))
+	return openWindow{window: w, task: task}, nil
+}
+
+// closeWindow finishes the window's task. A window that ran to its end, even
+// while the machine slept, is complete; one cut short is recorded partially.
+func (d *Daemon) closeWindow(ow openWindow, now time.Time) error {
+	finish := now
+	if finish.After(ow.window.End) {
+		finish = ow.window.End
+	}
+
+	task := ow.task
+	actual := finish.Sub(task.CreatedAt)
+	if actual < 0 {
+		actual = 0
+	}
+
+	percent := 100.0
+	if finish.Before(ow.window.End) {
+		planned := ow.window.End.Sub(task.CreatedAt)
+		percent = float64(actual) / float64(planned) * 100
+	}
+
+	task.SetActualDuration(int(actual.Seconds()))
+	task.SetCompletionPercent(percent)
+	task.SetFinishTime(finish)
+	task.SetFinalStatus()
+
+	if err := tasks.UpdateTaskAsFinished(d.Db, task); err != nil {
+		return err
+	}
+
+	slog.Info("Closed scheduled window.", "schedule", ow.window.Rule.Name, "task", task.TaskId)
+	return nil
+}
+
+// apply replaces the block when the set of groups changes.
+func (d *Daemon) apply(groups []string) error {
+	if slices.Equal(groups, d.groups) {
+		return nil
+	}
+
+	if d.blocker != nil {
+		if _, err := d.blocker.Stop(); err != nil {
+			return err
+		}
+		d.blocker = nil
+		d.groups = nil
+		slog.Info("Lifted scheduled block.")
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	b, err := d.NewBlocker(groups)
+	if err != nil {
+		return err
+	}
+
+	// another session's block is left alone, and taken over on a later tick
+	// once it ends.
+	if _, err := b.Start(); errors.Is(err, blocker.ErrBlockHeld) {
+		slog.Warn("Waiting for another block to end.", "error", err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	d.blocker = b
+	d.groups = groups
+	slog.Info("Applied scheduled block.", "groups", groups)
+	return nil
+}
@@ -0,0 +1,216 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/config"
+)
+
+const ClockFormat = "15:04"
+
+var dayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+}
+
+// Rule is a parsed config.Schedule. A window whose end is not after its start
+// runs past midnight and belongs to the day it starts on.
+type Rule struct {
+	Name   string
+	Days   [7]bool
+	Start  Clock
+	End    Clock
+	Groups []string
+}
+
+// Clock is a time of day.
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+// on returns the clock time on the given day.
+func (c Clock) on(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.Hour, c.Minute, 0, 0, day.Location())
+}
+
+func (c Clock) before(other Clock) bool {
+	return c.Hour < other.Hour || (c.Hour == other.Hour && c.Minute < other.Minute)
+}
+
+// Window is a single occurrence of a Rule.
+type Window struct {
+	Rule  Rule
+	Start time.Time
+	End   time.Time
+}
+
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse(ClockFormat, strings.TrimSpace(s))
+	if err != nil {
+		return Clock{}, fmt.Errorf("Error, invalid time %q (expected hh:mm)", s)
+	}
+
+	return Clock{Hour: t.Hour(), Minute: t.Minute()}, nil
+}
+
+// ParseDays reads day names (mon..sun), weekdays, weekends or daily.
+func ParseDays(days []string) ([7]bool, error) {
+	var out [7]bool
+
+	if len(days) == 0 {
+		return out, fmt.Errorf("Error, expected at least one day")
+	}
+
+	for _, day := range days {
+		weekdays, ok := dayNames[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return out, fmt.Errorf("Error, invalid day %q (expected mon..sun, weekdays, weekends or daily)", day)
+		}
+
+		for _, weekday := range weekdays {
+			out[weekday] = true
+		}
+	}
+
+	return out, nil
+}
+
+func ParseRule(s config.Schedule) (Rule, error) {
+	rule := Rule{Name: s.Name, Groups: s.Groups}
+
+	if s.Name == "" {
+		return rule, fmt.Errorf("Error, schedule is missing a name")
+	}
+
+	var err error
+	if rule.Days, err = ParseDays(s.Days); err != nil {
+		return rule, fmt.Errorf("Error in schedule %s: %w", s.Name, err)
+	}
+
+	if rule.Start, err = ParseClock(s.Start); err != nil {
+		return rule, fmt.Errorf("Error in schedule %s: %w", s.Name, err)
+	}
+
+	if rule.End, err = ParseClock(s.End); err != nil {
+		return rule, fmt.Errorf("Error in schedule %s: %w", s.Name, err)
+	}
+
+	if rule.Start == rule.End {
+		return rule, fmt.Errorf("Error in schedule %s: start and end are the same", s.Name)
+	}
+
+	return rule, nil
+}
+
+// ParseRules parses every schedule, rejecting duplicate names.
+func ParseRules(schedules []config.Schedule) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]bool)
+
+	for _, s := range schedules {
+		rule, err := ParseRule(s)
+		if err != nil {
+			return rules, err
+		}
+
+		if seen[rule.Name] {
+			return rules, fmt.Errorf("Error, duplicate schedule name %s", rule.Name)
+		}
+
+		seen[rule.Name] = true
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ActiveWindow returns the window of the rule containing t, if any.
+func (r Rule) ActiveWindow(t time.Time) (Window, bool) {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	// a window that started yesterday may still be running past midnight.
+	for _, day := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		if !r.Days[day.Weekday()] {
+			continue
+		}
+
+		start := r.Start.on(day)
+		end := r.End.on(day)
+		if !r.Start.before(r.End) {
+			end = r.End.on(day.AddDate(0, 0, 1))
+		}
+
+		if !t.Before(start) && t.Before(end) {
+			return Window{Rule: r, Start: start, End: end}, true
+		}
+	}
+
+	return Window{}, false
+}
+
+// DaysString formats the days a rule applies to, e.g. "mon,tue,wed".
+func (r Rule) DaysString() string {
+	var days []string
+	for weekday, ok := range r.Days {
+		if ok {
+			days = append(days, strings.ToLower(time.Weekday(weekday).String()[:3]))
+		}
+	}
+	return strings.Join(days, ",")
+}
+
+// ActiveGroups returns the sorted union of groups of the active windows.
+func ActiveGroups(windows []Window) []string {
+	seen := make(map[string]bool)
+	var groups []string
+
+	for _, w := range windows {
+		for _, group := range w.Rule.groups() {
+			if !seen[group] {
+				seen[group] = true
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	sort.Strings(groups)
+	return groups
+}
+
+// groups returns the rule's groups, falling back to the default groups, or
+// every group if there are no defaults.
+func (r Rule) groups() []string {
+	if len(r.Groups) > 0 {
+		return r.Groups
+	}
+
+	if defaults := config.GetDefaultGroups(); len(defaults) > 0 {
+		return defaults
+	}
+
+	var all []string
+	for name := range config.GetBlockGroups() {
+		all = append(all, name)
+	}
+	return all
+}
@@ -0,0 +1,309 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/blocker"
+	"github.com/connorkuljis/block-cli/internal/config"
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+)
+
+// 2024-01-01 is a Monday.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+func mustParseRule(t *testing.T, s config.Schedule) Rule {
+	t.Helper()
+	rule, err := ParseRule(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+func TestParseRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule config.Schedule
+		wantErr  bool
+	}{
+		{name: "Valid", schedule: config.Schedule{Name: "work", Days: []string{"weekdays"}, Start: "09:00", End: "12:00"}},
+		{name: "Overnight", schedule: config.Schedule{Name: "night", Days: []string{"daily"}, Start: "22:00", End: "06:00"}},
+		{name: "Missing name", schedule: config.Schedule{Days: []string{"mon"}, Start: "09:00", End: "12:00"}, wantErr: true},
+		{name: "Unknown day", schedule: config.Schedule{Name: "x", Days: []string{"someday"}, Start: "09:00", End: "12:00"}, wantErr: true},
+		{name: "No days", schedule: config.Schedule{Name: "x", Start: "09:00", End: "12:00"}, wantErr: true},
+		{name: "Bad time", schedule: config.Schedule{Name: "x", Days: []string{"mon"}, Start: "9am", End: "12:00"}, wantErr: true},
+		{name: "Empty window", schedule: config.Schedule{Name: "x", Days: []string{"mon"}, Start: "09:00", End: "09:00"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRule(tc.schedule)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	work := mustParseRule(t, config.Schedule{Name: "work", Days: []string{"weekdays"}, Start: "09:00", End: "12:00"})
+	night := mustParseRule(t, config.Schedule{Name: "night", Days: []string{"fri"}, Start: "22:00", End: "06:00"})
+
+	testCases := []struct {
+		name   string
+		rule   Rule
+		now    time.Time
+		active bool
+		start  time.Time
+	}{
+		{name: "Before window", rule: work, now: at(1, 8, 59), active: false},
+		{name: "Window start", rule: work, now: at(1, 9, 0), active: true, start: at(1, 9, 0)},
+		{name: "Inside window", rule: work, now: at(3, 11, 30), active: true, start: at(3, 9, 0)},
+		{name: "Window end is exclusive", rule: work, now: at(1, 12, 0), active: false},
+		{name: "Weekend", rule: work, now: at(6, 10, 0), active: false},
+		{name: "Overnight before midnight", rule: night, now: at(5, 23, 0), active: true, start: at(5, 22, 0)},
+		{name: "Overnight after midnight", rule: night, now: at(6, 5, 0), active: true, start: at(5, 22, 0)},
+		{name: "Overnight belongs to start day", rule: night, now: at(5, 5, 0), active: false},
+		{name: "Overnight after end", rule: night, now: at(6, 6, 0), active: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, ok := tc.rule.ActiveWindow(tc.now)
+			if ok != tc.active {
+				t.Fatalf("Expected active: %v, got: %v", tc.active, ok)
+			}
+			if ok && !w.Start.Equal(tc.start) {
+				t.Errorf("Expected: %v, got: %v", tc.start, w.Start)
+			}
+		})
+	}
+}
+
+func TestDaemonWindows(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	rules := []Rule{
+		mustParseRule(t, config.Schedule{Name: "work", Days: []string{"mon"}, Start: "09:00", End: "12:00", Groups: []string{"social"}}),
+		mustParseRule(t, config.Schedule{Name: "news", Days: []string{"mon"}, Start: "11:00", End: "13:00", Groups: []string{"news"}}),
+	}
+
+	var blockers []*blocker.NoopBlocker
+	now := at(1, 8, 0)
+	d := &Daemon{
+		Db:    sqlDb,
+		Rules: func() ([]Rule, error) { return rules, nil },
+		Now:   func() time.Time { return now },
+		NewBlocker: func(groups []string) (blocker.Blocker, error) {
+			b, err := blocker.NewNoopBlocker(blocker.Options{Groups: groups})
+			blockers = append(blockers, b)
+			return b, err
+		},
+	}
+
+	steps := []struct {
+		now    time.Time
+		groups []string
+	}{
+		{now: at(1, 8, 0), groups: nil},
+		{now: at(1, 9, 0), groups: []string{"social"}},
+		{now: at(1, 11, 30), groups: []string{"news", "social"}},
+		// the machine slept through the end of work.
+		{now: at(1, 12, 45), groups: []string{"news"}},
+		{now: at(1, 13, 0), groups: nil},
+	}
+
+	for _, step := range steps {
+		now = step.now
+		if err := d.Tick(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := d.Blocking(); !slices.Equal(got, step.groups) {
+			t.Errorf("At %s expected: %v, got: %v", now.Format(ClockFormat), step.groups, got)
+		}
+	}
+
+	for i, b := range blockers {
+		if starts, stops := b.Calls(); starts != 1 || stops != 1 {
+			t.Errorf("Expected blocker %d to start and stop once, got: %d, %d", i, starts, stops)
+		}
+	}
+
+	all, err := tasks.GetAllTasks(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 tasks, got: %d", len(all))
+	}
+
+	for _, task := range all {
+		if !task.FinishedAt.Valid || task.Completed != 1 {
+			t.Errorf("Expected %s to be finished and completed, got: %v, %d", task.TaskName, task.FinishedAt, task.Completed)
+		}
+	}
+}
+
+func TestDaemonResumesWindow(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	rules := []Rule{mustParseRule(t, config.Schedule{Name: "work", Days: []string{"mon"}, Start: "09:00", End: "12:00", Groups: []string{"social"}})}
+	newDaemon := func(now time.Time) *Daemon {
+		return &Daemon{
+			Db:    sqlDb,
+			Rules: func() ([]Rule, error) { return rules, nil },
+			Now:   func() time.Time { return now },
+			NewBlocker: func(groups []string) (blocker.Blocker, error) {
+				return blocker.NewNoopBlocker(blocker.Options{Groups: groups})
+			},
+		}
+	}
+
+	// the first daemon dies mid-window without closing it.
+	if err := newDaemon(at(1, 9, 30)).Tick(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDaemon(at(1, 10, 0))
+	if err := d.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := tasks.GetAllTasks(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 1 {
+		t.Fatalf("Expected 1 task, got: %d", len(all))
+	}
+
+	task := all[0]
+	if task.ActualDurationSeconds.Int64 != 30*60 {
+		t.Errorf("Expected: %d, got: %d", 30*60, task.ActualDurationSeconds.Int64)
+	}
+	if task.Completed != 0 {
+		t.Errorf("Expected a window cut short to be incomplete, got: %d", task.Completed)
+	}
+}
+
+func TestDaemonForeignLease(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	// the dns backend only writes the lease, so it runs without root.
+	home := t.TempDir()
+	config.Cfg = config.AppConfig{HiddenConfig: config.NewHiddenConfig(home), RootConfig: config.NewRootConfig(home)}
+	config.Cfg.HiddenConfig.Config.BlockGroups = map[string][]string{"social": {"reddit.com"}}
+	if err := os.MkdirAll(config.Cfg.RootConfig.Path, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	newSession := func() *blocker.DNSBlocker {
+		b, err := blocker.NewDNSBlocker(blocker.Options{Groups: []string{"social"}, Expiry: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	leaseHeldBy := func(want *blocker.Lease) {
+		t.Helper()
+		lease, err := blocker.ReadLease(config.GetLeasePath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == nil && lease != nil || want != nil && (lease == nil || !lease.Is(want)) {
+			t.Errorf("Expected lease: %v, got: %v", want, lease)
+		}
+	}
+
+	readLease := func() *blocker.Lease {
+		t.Helper()
+		lease, err := blocker.ReadLease(config.GetLeasePath())
+		if err != nil || lease == nil {
+			t.Fatalf("Expected a lease, got: %v, %v", lease, err)
+		}
+		return lease
+	}
+
+	rules := []Rule{mustParseRule(t, config.Schedule{Name: "work", Days: []string{"mon"}, Start: "09:00", End: "12:00", Groups: []string{"social"}})}
+	now := at(1, 9, 0)
+	d := &Daemon{
+		Db:    sqlDb,
+		Rules: func() ([]Rule, error) { return rules, nil },
+		Now:   func() time.Time { return now },
+		NewBlocker: func(groups []string) (blocker.Blocker, error) {
+			return blocker.NewDNSBlocker(blocker.Options{Groups: groups})
+		},
+	}
+
+	// a session started before the window keeps its block.
+	session := newSession()
+	if _, err := session.Start(); err != nil {
+		t.Fatal(err)
+	}
+	sessionLease := readLease()
+
+	if err := d.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Blocking(); got != nil {
+		t.Errorf("Expected: %v, got: %v", nil, got)
+	}
+	leaseHeldBy(sessionLease)
+
+	// once it ends the daemon takes over, and a new session can't start.
+	if _, err := session.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	now = at(1, 10, 0)
+	if err := d.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Blocking(); !slices.Equal(got, []string{"social"}) {
+		t.Errorf("Expected: %v, got: %v", []string{"social"}, got)
+	}
+	daemonLease := readLease()
+
+	session = newSession()
+	if _, err := session.Start(); !errors.Is(err, blocker.ErrBlockHeld) {
+		t.Errorf("Expected: %v, got: %v", blocker.ErrBlockHeld, err)
+	}
+	if _, err := session.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	leaseHeldBy(daemonLease)
+
+	// the daemon's block is lifted by hand and a session starts, so the end
+	// of the window leaves the session's block alone.
+	if _, err := blocker.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Start(); err != nil {
+		t.Fatal(err)
+	}
+	sessionLease = readLease()
+
+	now = at(1, 12, 0)
+	if err := d.Tick(); err != nil {
+		t.Fatal(err)
+	}
+	leaseHeldBy(sessionLease)
+
+	if _, err := session.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	leaseHeldBy(nil)
+}
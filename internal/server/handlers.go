@@ -39,6 +39,10 @@ func (s *Server) Routes() {
 	s.MuxRouter.HandleFunc("/tasks/edit/{taskId}", s.HandleEditTasks())
 	s.MuxRouter.HandleFunc("/daily/", s.HandleDaily())
 	s.MuxRouter.HandleFunc("/buckets", s.HandleBuckets())
+	s.MuxRouter.HandleFunc("/buckets/{bucketId}", s.HandleShowBucket())
+	s.MuxRouter.HandleFunc("/buckets/{bucketId}/rename", s.HandleRenameBucket())
+	s.MuxRouter.HandleFunc("/buckets/{bucketId}/archive", s.HandleArchiveBucket())
+	s.MuxRouter.HandleFunc("/buckets/{bucketId}/move", s.HandleMoveBucket())
 }
 
 func (s *Server) HandleHome() http.HandlerFunc {
@@ -138,7 +142,44 @@ func (s *Server) HandleShowTasks() http.HandlerFunc {
 			return
 		}
 
-		parcel := map[string]interface{}{"Task": task}
+		events, err := tasks.GetTaskEvents(s.Db, task.TaskId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// the task's pauses include those of its segments, like its totals.
+		pauses, err := tasks.GetPausesByTask(s.Db, []tasks.Task{task})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		parcel := map[string]interface{}{
+			"Task":   task,
+			"Events": events,
+			"Pauses": pauses[task.TaskId],
+			// set below for focus sessions of a pomodoro cycle.
+			"Cycle":      nil,
+			"CycleTasks": nil,
+		}
+
+		if task.CycleId.Valid {
+			cycle, err := tasks.GetCycleByID(s.Db, task.CycleId.Int64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			cycleTasks, err := tasks.GetTasksByCycleId(s.Db, cycle.CycleId)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			parcel["Cycle"] = cycle
+			parcel["CycleTasks"] = cycleTasks
+		}
 
 		htmlBytes, err := SafeTmplExec(t, "root", parcel)
 		if err != nil {
@@ -184,16 +225,21 @@ func (s *Server) HandleDaily() http.HandlerFunc {
 		// TODO: validate if overflows current date. if so, don't display the control in the html
 		dateNext := dateCurrent.Add(24 * time.Hour)
 
-		tasks, err := tasks.GetTasksByDate(s.Db, dateCurrent)
+		daily, err := tasks.GetTasksByDate(s.Db, dateCurrent)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		taskSummary := summariseTasks(tasks)
+		if err := tasks.AttachTags(s.Db, daily); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		taskSummary := summariseTasks(daily)
 
 		parcel := map[string]any{
-			"Tasks":       tasks,
+			"Tasks":       daily,
 			"DateCurrent": dateCurrent.Format(format),
 			"DatePrev":    datePrev.Format(format),
 			"DateNext":    dateNext.Format(format),
@@ -221,18 +267,132 @@ func (s *Server) HandleBuckets() http.HandlerFunc {
 		"buckets.html",
 	}
 
-	bucketsTemplate := s.ParseTemplates("index", nil, bucketsTemplateFragments...)
+	bucketsTemplate := s.ParseTemplates("index", funcMap, bucketsTemplateFragments...)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			tree, err := buckets.GetTree(s.Db, true)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			progress, err := buckets.GetAllProgress(s.Db, time.Now())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			parcel := map[string]any{"Buckets": buckets.Flatten(tree), "Progress": progress}
+
+			htmlBytes, err := SafeTmplExec(bucketsTemplate, "root", parcel)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			SendHTML(w, htmlBytes)
+		case "POST":
+			r.ParseForm()
+			bucket, err := buckets.CreateBucket(s.Db, r.FormValue("bucket_name"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if parentId, _ := strconv.ParseInt(r.FormValue("parent_id"), 10, 64); parentId != 0 {
+				if err := buckets.SetParent(s.Db, bucket.BucketId, parentId); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			http.Redirect(w, r, fmt.Sprintf("/buckets/%d", bucket.BucketId), http.StatusSeeOther)
+		default:
+			fmt.Fprintln(w, "Unsupported request type")
+		}
+	}
+}
+
+func (s *Server) HandleShowBucket() http.HandlerFunc {
+	page := []string{
+		"root.html",
+		"layout.html",
+		"head.html",
+		"header.html",
+		"footer.html",
+		"nav.html",
+		"tasks-table.html",
+		"show_bucket.html",
+	}
+
+	t := s.ParseTemplates("show-bucket", funcMap, page...)
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		buckets, err := buckets.GetAllBuckets(s.Db)
+		bucketId, err := strconv.ParseInt(r.PathValue("bucketId"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		bucket, err := buckets.GetBucketByID(s.Db, bucketId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		// the page covers the bucket's whole subtree.
+		subtree, err := buckets.DescendantIds(s.Db, bucketId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		subtreeTasks, err := tasks.GetTasksByBucketIds(s.Db, subtree)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tasks.AttachTags(s.Db, subtreeTasks); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		all, err := buckets.GetAllBuckets(s.Db)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		parcel := map[string]any{"Buckets": buckets}
+		var parent any
+		var children, parents []buckets.Bucket
+		inSubtree := make(map[int64]bool)
+		for _, id := range subtree {
+			inSubtree[id] = true
+		}
+		for _, b := range all {
+			switch {
+			case bucket.ParentId.Valid && b.BucketId == bucket.ParentId.Int64:
+				parent = b
+			case b.ParentId.Valid && b.ParentId.Int64 == bucketId:
+				children = append(children, b)
+			}
+			// a bucket can't move under itself or its children.
+			if !inSubtree[b.BucketId] && !b.Archived() {
+				parents = append(parents, b)
+			}
+		}
+
+		parcel := map[string]any{
+			"Bucket":      bucket,
+			"Parent":      parent,
+			"Children":    children,
+			"Parents":     parents,
+			"Tasks":       subtreeTasks,
+			"TaskSummary": summariseTasks(subtreeTasks),
+		}
 
-		htmlBytes, err := SafeTmplExec(bucketsTemplate, "root", parcel)
+		htmlBytes, err := SafeTmplExec(t, "root", parcel)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -242,6 +402,90 @@ func (s *Server) HandleBuckets() http.HandlerFunc {
 	}
 }
 
+func (s *Server) HandleRenameBucket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			fmt.Fprintln(w, "Unsupported request type")
+			return
+		}
+
+		bucketId, err := strconv.ParseInt(r.PathValue("bucketId"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.ParseForm()
+		err = buckets.RenameBucket(s.Db, bucketId, r.FormValue("bucket_name"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/buckets/%d", bucketId), http.StatusSeeOther)
+	}
+}
+
+// HandleMoveBucket moves a bucket under the form's parent_id, or to the top
+// level when it is empty.
+func (s *Server) HandleMoveBucket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			fmt.Fprintln(w, "Unsupported request type")
+			return
+		}
+
+		bucketId, err := strconv.ParseInt(r.PathValue("bucketId"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.ParseForm()
+		var parentId int64
+		if value := r.FormValue("parent_id"); value != "" {
+			parentId, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := buckets.SetParent(s.Db, bucketId, parentId); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/buckets/%d", bucketId), http.StatusSeeOther)
+	}
+}
+
+// HandleArchiveBucket archives a bucket, or restores it if the form sets
+// restore.
+func (s *Server) HandleArchiveBucket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			fmt.Fprintln(w, "Unsupported request type")
+			return
+		}
+
+		bucketId, err := strconv.ParseInt(r.PathValue("bucketId"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		r.ParseForm()
+		err = buckets.SetArchived(s.Db, bucketId, r.FormValue("restore") == "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/buckets/%d", bucketId), http.StatusSeeOther)
+	}
+}
+
 func (s *Server) HandleTasks() http.HandlerFunc {
 	tasksPageTemplateFragments := []string{
 		"root.html",
@@ -272,17 +516,53 @@ func (s *Server) HandleTasks() http.HandlerFunc {
 			}
 		}
 
-		tasks, err := tasks.GetRecentTasks(s.Db, time.Now().Truncate(24*time.Hour), daysBack)
+		statuses, err := tasks.ParseStatuses(r.URL.Query()["status"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filter := tasks.Filter{
+			Tags:     r.URL.Query()["tag"],
+			Note:     r.URL.Query().Get("note"),
+			Statuses: statuses,
+		}
+		if strMinRating := r.URL.Query().Get("min_rating"); strMinRating != "" {
+			minRating, err := strconv.Atoi(strMinRating)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter.MinRating = minRating
+		}
+
+		recent, err := tasks.GetRecentTasks(s.Db, time.Now().Truncate(24*time.Hour), daysBack)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		taskSummary := summariseTasks(tasks)
+		if err := tasks.AttachTags(s.Db, recent); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		recent = tasks.FilterTasks(recent, filter)
+
+		allTags, err := tasks.GetAllTags(s.Db)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		taskSummary := summariseTasks(recent)
 
 		parcel := map[string]any{
-			"Tasks":       tasks,
+			"Tasks":       recent,
 			"TaskSummary": taskSummary,
+			"Filter":      filter,
+			"AllTags":     allTags,
+			"Ratings":     []int{1, 2, 3, 4, 5},
+			"Statuses":    tasks.Statuses,
 		}
 
 		var htmlBytes []byte
@@ -312,22 +592,30 @@ type TasksSummary struct {
 	TaskAverageCompletionPercent float64
 }
 
+// summariseTasks totals the tasks. Average completion only counts tasks with
+// a completion percent, which stopwatch sessions don't have.
 func summariseTasks(tasks []tasks.Task) TasksSummary {
 	var taskCount int64
 	var taskTotalSeconds int64
 	var taskAverageSeconds int64
 	var taskTotalCompletionPercent float64
 	var taskAverageCompletionPercent float64
+	var estimatedCount int64
 
 	taskCount = int64(len(tasks))
 
 	if taskCount > 0 {
 		for i := range tasks {
 			taskTotalSeconds += tasks[i].ActualDurationSeconds.Int64
-			taskTotalCompletionPercent += tasks[i].CompletionPercent.Float64
+			if tasks[i].CompletionPercent.Valid {
+				taskTotalCompletionPercent += tasks[i].CompletionPercent.Float64
+				estimatedCount++
+			}
 		}
 		taskAverageSeconds = taskTotalSeconds / taskCount
-		taskAverageCompletionPercent = float64(taskTotalCompletionPercent) / float64(taskCount)
+		if estimatedCount > 0 {
+			taskAverageCompletionPercent = taskTotalCompletionPercent / float64(estimatedCount)
+		}
 	}
 
 	return TasksSummary{
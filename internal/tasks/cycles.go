@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Cycle groups the focus phases of one pomodoro run.
+type Cycle struct {
+	CycleId          int64     `db:"cycle_id"`
+	CycleName        string    `db:"cycle_name"`
+	CreatedAt        time.Time `db:"created_at"`
+	WorkSeconds      int64     `db:"work_seconds"`
+	BreakSeconds     int64     `db:"break_seconds"`
+	LongBreakSeconds int64     `db:"long_break_seconds"`
+	Rounds           int       `db:"rounds"`
+}
+
+func InsertCycle(db *sqlx.DB, cycle *Cycle) error {
+	query := `INSERT INTO Cycles
+	(
+	  cycle_name
+	, created_at
+	, work_seconds
+	, break_seconds
+	, long_break_seconds
+	, rounds
+	)
+	VALUES
+	(
+	  :cycle_name
+	, :created_at
+	, :work_seconds
+	, :break_seconds
+	, :long_break_seconds
+	, :rounds
+	)`
+
+	result, err := db.NamedExec(query, cycle)
+	if err != nil {
+		return err
+	}
+
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	cycle.CycleId = lastInsertID
+
+	return nil
+}
+
+func GetCycleByID(db *sqlx.DB, cycleId int64) (Cycle, error) {
+	var cycle Cycle
+	err := db.Get(&cycle, "SELECT * FROM Cycles WHERE cycle_id = ?", cycleId)
+	if err != nil {
+		return cycle, err
+	}
+
+	return cycle, nil
+}
+
+// GetTasksByCycleId returns the focus phases of a cycle in the order they ran.
+func GetTasksByCycleId(db *sqlx.DB, cycleId int64) ([]Task, error) {
+	var tasks []Task
+
+	err := db.Select(&tasks, "SELECT * FROM Tasks WHERE cycle_id = ? ORDER BY created_at ASC", cycleId)
+	if err != nil {
+		return tasks, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,207 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	EventStart  = "start"
+	EventPause  = "pause"
+	EventResume = "resume"
+	EventExtend = "extend"
+	EventCancel = "cancel"
+	EventFinish = "finish"
+
+	// EventCheckpoint periodically records a running task's elapsed time.
+	EventCheckpoint = "checkpoint"
+
+	// EventOverride flags a confirmed override of a strict session.
+	EventOverride = "override"
+)
+
+// TaskEvent records a change to a running session.
+type TaskEvent struct {
+	EventId        int64     `db:"event_id"`
+	TaskId         int64     `db:"task_id"`
+	EventType      string    `db:"event_type"`
+	CreatedAt      time.Time `db:"created_at"`
+	ElapsedSeconds int64     `db:"elapsed_seconds"`
+	DeltaSeconds   int64     `db:"delta_seconds"`
+}
+
+// Pauses summarises how often and for how long a task was paused.
+type Pauses struct {
+	Count    int
+	Duration time.Duration
+}
+
+func (p Pauses) Seconds() int64 {
+	return int64(p.Duration.Seconds())
+}
+
+// Add returns the pauses of p and other together.
+func (p Pauses) Add(other Pauses) Pauses {
+	return Pauses{Count: p.Count + other.Count, Duration: p.Duration + other.Duration}
+}
+
+func InsertTaskEvent(db *sqlx.DB, event *TaskEvent) error {
+	query := `INSERT INTO TaskEvents
+	(
+	  task_id
+	, event_type
+	, created_at
+	, elapsed_seconds
+	, delta_seconds
+	)
+	VALUES
+	(
+	  :task_id
+	, :event_type
+	, :created_at
+	, :elapsed_seconds
+	, :delta_seconds
+	)`
+
+	result, err := db.NamedExec(query, event)
+	if err != nil {
+		return err
+	}
+
+	event.EventId, err = result.LastInsertId()
+	return err
+}
+
+func GetTaskEvents(db *sqlx.DB, taskId int64) ([]TaskEvent, error) {
+	var events []TaskEvent
+
+	err := db.Select(&events, "SELECT * FROM TaskEvents WHERE task_id = ? ORDER BY created_at, event_id", taskId)
+	if err != nil {
+		return events, err
+	}
+
+	return events, nil
+}
+
+// GetTaskPauses summarises the pauses of a single task.
+func GetTaskPauses(db *sqlx.DB, taskId int64) (Pauses, error) {
+	events, err := GetTaskEvents(db, taskId)
+	if err != nil {
+		return Pauses{}, err
+	}
+
+	return SummarisePauses(events, time.Now()), nil
+}
+
+// GetPausesByTask summarises the pauses of each task, keyed by task id. A task
+// with segments counts their pauses too. Tasks that were never paused are
+// absent.
+func GetPausesByTask(db *sqlx.DB, tasks []Task) (map[int64]Pauses, error) {
+	pauses := make(map[int64]Pauses)
+	if len(tasks) == 0 {
+		return pauses, nil
+	}
+
+	var ids []int64
+	for _, task := range tasks {
+		ids = append(ids, task.TaskId)
+		for _, segment := range task.Segments {
+			ids = append(ids, segment.TaskId)
+		}
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM TaskEvents WHERE task_id IN (?) ORDER BY task_id, created_at, event_id", ids)
+	if err != nil {
+		return pauses, err
+	}
+
+	var events []TaskEvent
+	if err := db.Select(&events, db.Rebind(query), args...); err != nil {
+		return pauses, err
+	}
+
+	byTask := make(map[int64][]TaskEvent)
+	for _, event := range events {
+		byTask[event.TaskId] = append(byTask[event.TaskId], event)
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		p := SummarisePauses(byTask[task.TaskId], now)
+		for _, segment := range task.Segments {
+			p = p.Add(SummarisePauses(byTask[segment.TaskId], now))
+		}
+		if p.Count > 0 {
+			pauses[task.TaskId] = p
+		}
+	}
+
+	return pauses, nil
+}
+
+// AttachOverrides sets the strict overrides of each task from its override
+// events. Tasks from before overrides were recorded as events keep the count
+// stored when they finished.
+func AttachOverrides(db *sqlx.DB, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.TaskId
+	}
+
+	query, args, err := sqlx.In("SELECT task_id, COUNT(*) AS overrides FROM TaskEvents WHERE event_type = ? AND task_id IN (?) GROUP BY task_id", EventOverride, ids)
+	if err != nil {
+		return err
+	}
+
+	var counts []struct {
+		TaskId    int64 `db:"task_id"`
+		Overrides int   `db:"overrides"`
+	}
+	if err := db.Select(&counts, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	byTask := make(map[int64]int, len(counts))
+	for _, count := range counts {
+		byTask[count.TaskId] = count.Overrides
+	}
+
+	for i := range tasks {
+		if n, ok := byTask[tasks[i].TaskId]; ok {
+			tasks[i].StrictOverrides = n
+		}
+	}
+
+	return nil
+}
+
+// SummarisePauses counts pauses and adds up the time between each pause and
+// the event that followed it. A pause with nothing after it is still running
+// and counts until now.
+func SummarisePauses(events []TaskEvent, now time.Time) Pauses {
+	var p Pauses
+	var pausedAt *time.Time
+
+	for _, event := range events {
+		switch {
+		case event.EventType == EventPause:
+			p.Count++
+			at := event.CreatedAt
+			pausedAt = &at
+		case pausedAt != nil && event.EventType != EventExtend && event.EventType != EventOverride:
+			p.Duration += event.CreatedAt.Sub(*pausedAt)
+			pausedAt = nil
+		}
+	}
+
+	if pausedAt != nil {
+		p.Duration += now.Sub(*pausedAt)
+	}
+
+	return p
+}
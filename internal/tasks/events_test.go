@@ -0,0 +1,98 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+)
+
+func TestSummarisePauses(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int, eventType string) TaskEvent {
+		return TaskEvent{EventType: eventType, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+	now := start.Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		events   []TaskEvent
+		count    int
+		duration time.Duration
+	}{
+		{name: "No events", count: 0, duration: 0},
+		{name: "Never paused", events: []TaskEvent{at(0, EventStart), at(25, EventFinish)}, count: 0, duration: 0},
+		{
+			name:     "Paused twice",
+			events:   []TaskEvent{at(0, EventStart), at(5, EventPause), at(7, EventResume), at(10, EventPause), at(15, EventResume), at(30, EventFinish)},
+			count:    2,
+			duration: 7 * time.Minute,
+		},
+		{
+			name:     "Extended while paused",
+			events:   []TaskEvent{at(0, EventStart), at(5, EventPause), at(6, EventExtend), at(8, EventResume)},
+			count:    1,
+			duration: 3 * time.Minute,
+		},
+		{
+			name:     "Overridden while paused",
+			events:   []TaskEvent{at(0, EventStart), at(5, EventPause), at(5, EventOverride), at(9, EventResume)},
+			count:    1,
+			duration: 4 * time.Minute,
+		},
+		{
+			name:     "Cancelled while paused",
+			events:   []TaskEvent{at(0, EventStart), at(5, EventPause), at(20, EventCancel)},
+			count:    1,
+			duration: 15 * time.Minute,
+		},
+		{
+			name:     "Still paused",
+			events:   []TaskEvent{at(0, EventStart), at(50, EventPause)},
+			count:    1,
+			duration: 10 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SummarisePauses(tc.events, now)
+			if got.Count != tc.count || got.Duration != tc.duration {
+				t.Errorf("Expected: %d %v, got: %d %v", tc.count, tc.duration, got.Count, got.Duration)
+			}
+		})
+	}
+}
+
+func TestAttachOverrides(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	start := time.Now().Add(-time.Hour).Round(time.Second)
+
+	overridden := NewTask("deep work", 1800, true, false, start)
+	legacy := NewTask("old session", 1800, true, false, start)
+	for _, task := range []*Task{overridden, legacy} {
+		if err := InsertTask(sqlDb, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, minutes := range []int{5, 12} {
+		event := &TaskEvent{TaskId: overridden.TaskId, EventType: EventOverride, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+		if err := InsertTaskEvent(sqlDb, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// counts stored before overrides were events are kept.
+	legacy.StrictOverrides = 3
+
+	all := []Task{*overridden, *legacy}
+	if err := AttachOverrides(sqlDb, all); err != nil {
+		t.Fatal(err)
+	}
+
+	if all[0].StrictOverrides != 2 || all[1].StrictOverrides != 3 {
+		t.Errorf("Expected: 2 3, got: %v %v", all[0].StrictOverrides, all[1].StrictOverrides)
+	}
+}
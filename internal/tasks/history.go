@@ -3,14 +3,21 @@ package tasks
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 )
 
-func RenderTable(tasks []Task) {
+// NoteWidth is how much of a note fits in the history table.
+const NoteWidth = 40
+
+// RenderTable prints tasks with their pauses, keyed by task id. A task with
+// segments shows its totals across them.
+func RenderTable(tasks []Task, pauses map[int64]Pauses) {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Date", "Name", "Planned (min)", "Actual (min)", "Completion Percent", "Completed"})
+	table.SetHeader([]string{"ID", "Date", "Name", "Planned (min)", "Actual (min)", "Completion Percent", "Completed", "Status", "Pauses", "Strict", "Tags", "Rating", "Note"})
 	table.SetAutoWrapText(false)
 	table.SetAutoFormatHeaders(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
@@ -24,30 +31,75 @@ func RenderTable(tasks []Task) {
 	table.SetNoWhiteSpace(true)
 
 	totalMinutes := 0.0
+	strictSessions, brokenSessions := 0, 0
 	for _, task := range tasks {
 		id := fmt.Sprint(task.TaskId)
 		name := task.TaskName
-		planned := fmt.Sprintf("%d", task.EstimatedDurationSeconds)
-		actual := fmt.Sprintf("%d", task.ActualDurationSeconds.Int64)
+		if len(task.Segments) > 0 {
+			name = fmt.Sprintf("%s (%d segments)", name, len(task.Segments)+1)
+		} else if task.IsSegment() {
+			name = fmt.Sprintf("%s (resumes %d)", name, task.ParentTaskId.Int64)
+		}
+		planned := "—"
+		if !task.IsStopwatch() {
+			planned = fmt.Sprintf("%d", task.EstimatedDurationSeconds.Int64)
+			if adjustment := task.EstimateAdjustment(); adjustment != 0 {
+				planned = fmt.Sprintf("%d (%+d)", task.EstimatedDurationSeconds.Int64, adjustment)
+			}
+		}
+		actual := fmt.Sprintf("%d", task.TotalSeconds())
 		date := task.CreatedAt.Format("Mon Jan 02 15:04:05")
 
-		completionPercent := fmt.Sprintf("%.2f%%", task.CompletionPercent.Float64)
+		completionPercent := "—"
+		if percent := task.TotalPercent(); percent.Valid {
+			completionPercent = fmt.Sprintf("%.2f%%", percent.Float64)
+		}
 
 		var completed string
-		if task.Completed == 1 {
+		if task.TotalStatus() == StatusCompleted {
 			completed = "✅"
 		}
 
-		row := []string{id, date, name, planned, actual, completionPercent, completed}
+		var strict string
+		if task.Strict == 1 {
+			strictSessions++
+			strict = "🔒"
+			if task.StrictOverrides > 0 {
+				brokenSessions++
+				strict = fmt.Sprintf("broken x%d", task.StrictOverrides)
+			}
+		}
+
+		var paused string
+		if p, ok := pauses[task.TaskId]; ok {
+			paused = fmt.Sprintf("%d (%s)", p.Count, p.Duration.Round(time.Second))
+		}
 
-		if task.ActualDurationSeconds.Valid {
-			totalMinutes += float64(task.ActualDurationSeconds.Int64)
+		var rating string
+		if task.Rating.Valid {
+			rating = strings.Repeat("★", int(task.Rating.Int64))
 		}
 
+		row := []string{id, date, name, planned, actual, completionPercent, completed, task.TotalStatus(), paused, strict, strings.Join(task.Tags, ","), rating, truncate(task.Note.String, NoteWidth)}
+
+		totalMinutes += float64(task.TotalSeconds())
+
 		table.Append(row)
 	}
 	table.Render()
 
 	fmt.Println()
 	color.Cyan(fmt.Sprintf("Total: %.0f minutes", totalMinutes))
+	if strictSessions > 0 {
+		color.Cyan(fmt.Sprintf("Strict sessions: %d, broken: %d", strictSessions, brokenSessions))
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n-1]) + "…"
 }
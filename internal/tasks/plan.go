@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// notPlanned keeps planned tasks, which haven't run, out of a query.
+const notPlanned = "status IS NOT '" + StatusPlanned + "'"
+
+// ErrNothingPlanned is returned when the plan is empty.
+var ErrNothingPlanned = errors.New("Error, nothing planned, add tasks with block plan add")
+
+func (task Task) IsPlanned() bool {
+	return task.Status.Valid && task.Status.String == StatusPlanned
+}
+
+// PlanTask queues a task to be run later by block next.
+func PlanTask(db *sqlx.DB, task *Task) error {
+	task.Status = sql.NullString{String: StatusPlanned, Valid: true}
+
+	if err := InsertTask(db, task); err != nil {
+		return fmt.Errorf("Error planning task %s: %w", task.TaskName, err)
+	}
+
+	if len(task.Tags) > 0 {
+		return SetTaskTags(db, task.TaskId, task.Tags)
+	}
+
+	return nil
+}
+
+// GetPlannedTasks returns the plan in the order the tasks will run, which is
+// the order they were added.
+func GetPlannedTasks(db *sqlx.DB) ([]Task, error) {
+	var tasks []Task
+
+	err := db.Select(&tasks, "SELECT * FROM Tasks WHERE status = ? ORDER BY created_at, task_id", StatusPlanned)
+	if err != nil {
+		return tasks, err
+	}
+
+	return tasks, nil
+}
+
+// GetNextPlannedTask returns the task block next would run, or
+// ErrNothingPlanned.
+func GetNextPlannedTask(db *sqlx.DB) (Task, error) {
+	var task Task
+
+	err := db.Get(&task, "SELECT * FROM Tasks WHERE status = ? ORDER BY created_at, task_id LIMIT 1", StatusPlanned)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, ErrNothingPlanned
+	}
+	if err != nil {
+		return task, err
+	}
+
+	return task, nil
+}
+
+// StartPlannedTask takes a task off the plan as it starts running. It fails if
+// the task is no longer planned, e.g. another block next took it first.
+func StartPlannedTask(db *sqlx.DB, task *Task, startedAt time.Time) error {
+	query := "UPDATE Tasks SET status = ?, pid = ?, created_at = ? WHERE task_id = ? AND status = ?"
+
+	result, err := db.Exec(query, StatusRunning, os.Getpid(), startedAt, task.TaskId, StatusPlanned)
+	if err != nil {
+		return fmt.Errorf("Error starting planned task %d: %w", task.TaskId, err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("Error, task %d is no longer planned", task.TaskId)
+	}
+
+	task.SetRunning(os.Getpid())
+	task.CreatedAt = startedAt
+
+	return nil
+}
+
+// UnplanTask removes a task from the plan.
+func UnplanTask(db *sqlx.DB, taskId int64) error {
+	result, err := db.Exec("DELETE FROM Tasks WHERE task_id = ? AND status = ?", taskId, StatusPlanned)
+	if err != nil {
+		return fmt.Errorf("Error removing task %d from the plan: %w", taskId, err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("Error, task %d isn't planned", taskId)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+)
+
+func TestPlanQueue(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	if _, err := GetNextPlannedTask(sqlDb); !errors.Is(err, ErrNothingPlanned) {
+		t.Errorf("Expected: %v, got: %v", ErrNothingPlanned, err)
+	}
+
+	now := time.Now()
+	for i, name := range []string{"inbox", "review", "docs"} {
+		task := NewTask(name, 600, true, false, now.Add(time.Duration(i)*time.Second))
+		if err := PlanTask(sqlDb, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// planned tasks haven't run, so they stay out of history.
+	recent, err := GetRecentTasks(sqlDb, now, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("Expected: no recent tasks, got: %v", len(recent))
+	}
+
+	next, err := GetNextPlannedTask(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.TaskName != "inbox" || !next.IsPlanned() {
+		t.Errorf("Expected: planned inbox, got: %v %v", next.TaskName, next.Status)
+	}
+
+	if err := StartPlannedTask(sqlDb, &next, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartPlannedTask(sqlDb, &next, now.Add(time.Minute)); err == nil {
+		t.Errorf("Expected starting a task twice to fail")
+	}
+
+	if err := UnplanTask(sqlDb, next.TaskId); err == nil {
+		t.Errorf("Expected removing a started task from the plan to fail")
+	}
+
+	planned, err := GetPlannedTasks(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, task := range planned {
+		names = append(names, task.TaskName)
+	}
+	if len(names) != 2 || names[0] != "review" || names[1] != "docs" {
+		t.Errorf("Expected: [review docs], got: %v", names)
+	}
+
+	recent, err = GetRecentTasks(sqlDb, now, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].TaskId != next.TaskId {
+		t.Errorf("Expected: the started task in history, got: %v", recent)
+	}
+}
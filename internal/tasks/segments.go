@@ -0,0 +1,181 @@
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// IsSegment reports whether the task resumed another task.
+func (task Task) IsSegment() bool {
+	return task.ParentTaskId.Valid
+}
+
+// latest is the task's most recent session.
+func (task Task) latest() Task {
+	if len(task.Segments) == 0 {
+		return task
+	}
+	return task.Segments[len(task.Segments)-1]
+}
+
+// TotalSeconds is the time the task ran across all of its segments.
+func (task Task) TotalSeconds() int64 {
+	total := task.ActualDurationSeconds.Int64
+	for _, segment := range task.Segments {
+		total += segment.ActualDurationSeconds.Int64
+	}
+	return total
+}
+
+// TargetSeconds is the task's estimate, including any extensions made while
+// its segments ran. Stopwatch tasks have none.
+func (task Task) TargetSeconds() int64 {
+	target := task.EstimatedDurationSeconds.Int64
+	for _, segment := range task.Segments {
+		target += segment.EstimateAdjustment()
+	}
+	return target
+}
+
+// RemainingSeconds is how much of the estimate is left to run.
+func (task Task) RemainingSeconds() int64 {
+	return max(0, task.TargetSeconds()-task.TotalSeconds())
+}
+
+// TotalPercent is how much of the estimate was used across all segments, or
+// null for stopwatch tasks.
+func (task Task) TotalPercent() sql.NullFloat64 {
+	if len(task.Segments) == 0 {
+		return task.CompletionPercent
+	}
+	if task.IsStopwatch() || task.TargetSeconds() <= 0 {
+		return sql.NullFloat64{Valid: false}
+	}
+
+	percent := float64(task.TotalSeconds()) / float64(task.TargetSeconds()) * 100
+	if task.latest().Completed == 1 {
+		percent = 100
+	}
+
+	return sql.NullFloat64{Float64: min(100, percent), Valid: true}
+}
+
+// TotalStatus is the status of the task's most recent session.
+func (task Task) TotalStatus() string {
+	return task.latest().StatusName()
+}
+
+// NewSegment returns a task that resumes parent for the rest of its estimate,
+// with the same name, bucket, tags and settings.
+func NewSegment(parent Task, createdAt time.Time) (*Task, error) {
+	switch status := parent.TotalStatus(); {
+	case parent.IsSegment():
+		return nil, fmt.Errorf("Error, task %d is a segment of task %d, resume that instead", parent.TaskId, parent.ParentTaskId.Int64)
+	case parent.IsPlanned():
+		return nil, fmt.Errorf("Error, task %d is planned, start it with block next", parent.TaskId)
+	case status == StatusRunning || status == StatusPaused:
+		return nil, fmt.Errorf("Error, task %d is still %s", parent.TaskId, status)
+	case status == StatusCompleted:
+		return nil, fmt.Errorf("Error, task %d is already completed", parent.TaskId)
+	}
+
+	var segment *Task
+	if parent.IsStopwatch() {
+		segment = NewStopwatchTask(parent.TaskName, parent.BlockerEnabled == 1, parent.ScreenEnabled == 1, createdAt)
+	} else {
+		remaining := parent.RemainingSeconds()
+		if remaining == 0 {
+			return nil, fmt.Errorf("Error, task %d has no time left on its estimate", parent.TaskId)
+		}
+		segment = NewTask(parent.TaskName, remaining, parent.BlockerEnabled == 1, parent.ScreenEnabled == 1, createdAt)
+	}
+
+	segment.ParentTaskId = sql.NullInt64{Int64: parent.TaskId, Valid: true}
+	segment.BucketId = parent.BucketId
+	segment.Strict = parent.Strict
+	segment.Tags = parent.Tags
+
+	return segment, nil
+}
+
+// GetRootTask returns the task a segment resumed, or the task itself, with
+// its segments and tags.
+func GetRootTask(db *sqlx.DB, id int64) (Task, error) {
+	task, err := GetTaskByID(db, id)
+	if err != nil {
+		return task, err
+	}
+
+	if task.IsSegment() {
+		task, err = GetTaskByID(db, task.ParentTaskId.Int64)
+		if err != nil {
+			return task, err
+		}
+	}
+
+	withTags := []Task{task}
+	if err := AttachTags(db, withTags); err != nil {
+		return task, err
+	}
+
+	return withTags[0], nil
+}
+
+// GetSegments returns the sessions that resumed a task, oldest first.
+func GetSegments(db *sqlx.DB, parentId int64) ([]Task, error) {
+	var segments []Task
+
+	err := db.Select(&segments, "SELECT * FROM Tasks WHERE parent_task_id = ? ORDER BY created_at, task_id", parentId)
+	if err != nil {
+		return segments, fmt.Errorf("Error getting segments of task %d: %w", parentId, err)
+	}
+
+	return segments, nil
+}
+
+// FoldSegments attaches the segments of each task in tasks to it and drops
+// those segments from the list, so each task is one row with its totals. A
+// segment whose task isn't listed is kept as it is.
+func FoldSegments(db *sqlx.DB, tasks []Task) ([]Task, error) {
+	listed := make(map[int64]bool)
+	var ids []int64
+	for _, task := range tasks {
+		listed[task.TaskId] = true
+		if !task.IsSegment() {
+			ids = append(ids, task.TaskId)
+		}
+	}
+
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM Tasks WHERE parent_task_id IN (?) ORDER BY created_at, task_id", ids)
+	if err != nil {
+		return tasks, err
+	}
+
+	var segments []Task
+	if err := db.Select(&segments, db.Rebind(query), args...); err != nil {
+		return tasks, fmt.Errorf("Error getting segments: %w", err)
+	}
+
+	byParent := make(map[int64][]Task)
+	for _, segment := range segments {
+		byParent[segment.ParentTaskId.Int64] = append(byParent[segment.ParentTaskId.Int64], segment)
+	}
+
+	var folded []Task
+	for _, task := range tasks {
+		if task.IsSegment() && listed[task.ParentTaskId.Int64] {
+			continue
+		}
+		task.Segments = byParent[task.TaskId]
+		folded = append(folded, task)
+	}
+
+	return folded, nil
+}
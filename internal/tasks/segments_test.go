@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+)
+
+func TestResumeSegments(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	start := time.Now().Add(-time.Hour).Round(time.Second)
+
+	// finish runs task for seconds of its estimate and stores it.
+	finish := func(task *Task, seconds int) {
+		task.SetRunning(1)
+		if err := InsertTask(sqlDb, task); err != nil {
+			t.Fatal(err)
+		}
+		task.SetActualDuration(seconds)
+		task.SetCompletionPercent(float64(seconds) / float64(task.EstimatedDurationSeconds.Int64) * 100)
+		task.SetFinishTime(task.CreatedAt.Add(time.Duration(seconds) * time.Second))
+		task.SetFinalStatus()
+		if err := UpdateTaskAsFinished(sqlDb, *task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// pause records a pause of minutes, a minute into task.
+	pause := func(task *Task, minutes int) {
+		at := task.CreatedAt.Add(time.Minute)
+		for _, event := range []TaskEvent{
+			{TaskId: task.TaskId, EventType: EventPause, CreatedAt: at},
+			{TaskId: task.TaskId, EventType: EventResume, CreatedAt: at.Add(time.Duration(minutes) * time.Minute)},
+		} {
+			if err := InsertTaskEvent(sqlDb, &event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	parent := NewTask("write docs", 1800, false, false, start)
+	finish(parent, 600)
+	pause(parent, 2)
+
+	root, err := GetRootTask(sqlDb, parent.TaskId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := NewSegment(root, start.Add(20*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.EstimatedDurationSeconds.Int64 != 1200 {
+		t.Errorf("Expected: %v, got: %v", 1200, first.EstimatedDurationSeconds.Int64)
+	}
+	finish(first, 300)
+	pause(first, 3)
+
+	// resuming by a segment's id continues the task it resumed.
+	root, err = GetRootTask(sqlDb, first.TaskId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.TaskId != parent.TaskId || len(root.Segments) != 1 {
+		t.Fatalf("Expected: task %v with 1 segment, got: task %v with %v", parent.TaskId, root.TaskId, len(root.Segments))
+	}
+	if root.TotalSeconds() != 900 || root.TotalPercent().Float64 != 50 || root.TotalStatus() != StatusCancelled {
+		t.Errorf("Expected: 900s 50%% cancelled, got: %vs %v%% %v", root.TotalSeconds(), root.TotalPercent().Float64, root.TotalStatus())
+	}
+
+	second, err := NewSegment(root, start.Add(40*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.EstimatedDurationSeconds.Int64 != 900 {
+		t.Errorf("Expected: %v, got: %v", 900, second.EstimatedDurationSeconds.Int64)
+	}
+	finish(second, 900)
+
+	all, err := GetAllTasks(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folded, err := FoldSegments(sqlDb, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folded) != 1 {
+		t.Fatalf("Expected: %v, got: %v", 1, len(folded))
+	}
+	if folded[0].TotalSeconds() != 1800 || folded[0].TotalPercent().Float64 != 100 || folded[0].TotalStatus() != StatusCompleted {
+		t.Errorf("Expected: 1800s 100%% completed, got: %vs %v%% %v", folded[0].TotalSeconds(), folded[0].TotalPercent().Float64, folded[0].TotalStatus())
+	}
+
+	// the folded row counts the pauses of every segment.
+	pauses, err := GetPausesByTask(sqlDb, folded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := pauses[parent.TaskId]; p.Count != 2 || p.Duration != 5*time.Minute {
+		t.Errorf("Expected: 2 pauses for 5m0s, got: %v for %v", p.Count, p.Duration)
+	}
+
+	if _, err := NewSegment(folded[0], time.Now()); err == nil {
+		t.Errorf("Expected resuming a completed task to fail")
+	}
+
+	// deleting the task deletes its segments.
+	if _, err := DeleteTaskByID(sqlDb, fmt.Sprint(parent.TaskId)); err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := GetSegments(sqlDb, parent.TaskId); len(segments) != 0 {
+		t.Errorf("Expected: no segments, got: %v", len(segments))
+	}
+}
@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A task's status follows its lifecycle: planned tasks start running, may be
+// paused and resumed, and end completed or cancelled. A task whose process
+// died while it ran is abandoned once reconciled.
+const (
+	StatusPlanned   = "planned"
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+	StatusAbandoned = "abandoned"
+)
+
+// Statuses lists every status in lifecycle order.
+var Statuses = []string{StatusPlanned, StatusRunning, StatusPaused, StatusCompleted, StatusCancelled, StatusAbandoned}
+
+// ParseStatuses checks and lowercases statuses, which may be comma separated.
+func ParseStatuses(values []string) ([]string, error) {
+	var out []string
+	for _, value := range values {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if status == "" {
+				continue
+			}
+			if !validStatus(status) {
+				return out, fmt.Errorf("Error, invalid status %q (expected one of %s)", status, strings.Join(Statuses, ", "))
+			}
+			out = append(out, status)
+		}
+	}
+	return out, nil
+}
+
+func validStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusName is the task's status, or an empty string if it has none.
+func (task Task) StatusName() string {
+	return task.Status.String
+}
+
+// SetRunning marks the task as running in the process pid.
+func (task *Task) SetRunning(pid int) {
+	task.Status = sql.NullString{String: StatusRunning, Valid: true}
+	task.Pid = sql.NullInt64{Int64: int64(pid), Valid: true}
+}
+
+// SetFinalStatus marks a task that has ended as completed or cancelled.
+func (task *Task) SetFinalStatus() {
+	status := StatusCancelled
+	if task.Completed == 1 {
+		status = StatusCompleted
+	}
+	task.Status = sql.NullString{String: status, Valid: true}
+}
+
+// ReopenTask marks an unfinished or abandoned task as running again in the
+// process pid.
+func ReopenTask(db *sqlx.DB, task *Task, pid int) error {
+	task.SetRunning(pid)
+	task.FinishedAt = sql.NullTime{Valid: false}
+
+	_, err := db.Exec("UPDATE Tasks SET status = ?, pid = ?, finished_at = NULL WHERE task_id = ?", task.Status, task.Pid, task.TaskId)
+	if err != nil {
+		return fmt.Errorf("Error reopening task %d: %w", task.TaskId, err)
+	}
+
+	return nil
+}
+
+// UpdateStatus stores a running task's status as it is paused and resumed.
+func UpdateStatus(db *sqlx.DB, taskId int64, status string) error {
+	_, err := db.Exec("UPDATE Tasks SET status = ? WHERE task_id = ?", status, taskId)
+	if err != nil {
+		return fmt.Errorf("Error updating status of task %d: %w", taskId, err)
+	}
+
+	return nil
+}
+
+// ReconcileOrphans finishes tasks left running or paused by a process that
+// is gone, e.g. after a crash. Their duration is inferred from their events.
+// A task that reached its estimate is completed, any other is abandoned.
+func ReconcileOrphans(db *sqlx.DB, alive func(pid int) bool) (int, error) {
+	var orphans []Task
+	err := db.Select(&orphans, "SELECT * FROM Tasks WHERE status IN (?, ?)", StatusRunning, StatusPaused)
+	if err != nil {
+		return 0, err
+	}
+
+	var reconciled int
+	for _, task := range orphans {
+		if task.Pid.Valid && alive(int(task.Pid.Int64)) {
+			continue
+		}
+
+		events, err := GetTaskEvents(db, task.TaskId)
+		if err != nil {
+			return reconciled, err
+		}
+
+		InferFromEvents(&task, events)
+		if err := UpdateTaskAsFinished(db, task); err != nil {
+			return reconciled, fmt.Errorf("Error reconciling task %d: %w", task.TaskId, err)
+		}
+
+		slog.Info("Reconciled task left running.", "task", task.TaskId, "status", task.Status.String, "seconds", task.ActualDurationSeconds.Int64)
+		reconciled++
+	}
+
+	return reconciled, nil
+}
+
+// InferFromEvents finishes an orphaned task as of its last recorded event,
+// counting only the running time the events account for. A duration already
+// stored, e.g. on a task from before events were recorded or one edited by
+// hand, is kept unless the events account for more.
+func InferFromEvents(task *Task, events []TaskEvent) {
+	elapsed := task.ActualDurationSeconds.Int64
+	finishedAt := task.CreatedAt.Add(time.Duration(elapsed) * time.Second)
+	for _, event := range events {
+		if event.ElapsedSeconds > elapsed {
+			elapsed = event.ElapsedSeconds
+		}
+		if event.CreatedAt.After(finishedAt) {
+			finishedAt = event.CreatedAt
+		}
+	}
+
+	percent := -1.0
+	if !task.IsStopwatch() && task.EstimatedDurationSeconds.Int64 > 0 {
+		percent = min(100, float64(elapsed)/float64(task.EstimatedDurationSeconds.Int64)*100)
+	}
+
+	task.SetActualDuration(int(elapsed))
+	task.SetCompletionPercent(percent)
+	task.SetFinishTime(finishedAt)
+
+	task.Status = sql.NullString{String: StatusAbandoned, Valid: true}
+	if task.Completed == 1 {
+		task.Status.String = StatusCompleted
+	}
+}
+
+// RecordCheckpoint stores how long a running task has run, replacing its
+// previous checkpoint, so a crash loses at most one checkpoint interval.
+func RecordCheckpoint(db *sqlx.DB, taskId int64, at time.Time, elapsedSeconds int64) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM TaskEvents WHERE task_id = ? AND event_type = ?", taskId, EventCheckpoint); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO TaskEvents (task_id, event_type, created_at, elapsed_seconds) VALUES (?, ?, ?, ?)"
+	if _, err := tx.Exec(query, taskId, EventCheckpoint, at, elapsedSeconds); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/connorkuljis/block-cli/internal/db/dbtest"
+)
+
+func TestInferFromEvents(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		estimate    int64
+		stored      int
+		events      []TaskEvent
+		wantSeconds int64
+		wantStatus  string
+		wantFinish  time.Time
+	}{
+		{
+			name:       "No events",
+			estimate:   600,
+			wantStatus: StatusAbandoned,
+			wantFinish: start,
+		},
+		{
+			name:     "Checkpoint",
+			estimate: 600,
+			events: []TaskEvent{
+				{EventType: EventPause, CreatedAt: start.Add(2 * time.Minute), ElapsedSeconds: 120},
+				{EventType: EventCheckpoint, CreatedAt: start.Add(5 * time.Minute), ElapsedSeconds: 240},
+			},
+			wantSeconds: 240,
+			wantStatus:  StatusAbandoned,
+			wantFinish:  start.Add(5 * time.Minute),
+		},
+		{
+			name:        "Stored duration without events",
+			estimate:    600,
+			stored:      420,
+			wantSeconds: 420,
+			wantStatus:  StatusAbandoned,
+			wantFinish:  start.Add(7 * time.Minute),
+		},
+		{
+			name:     "Events beyond stored duration",
+			estimate: 600,
+			stored:   60,
+			events: []TaskEvent{
+				{EventType: EventCheckpoint, CreatedAt: start.Add(5 * time.Minute), ElapsedSeconds: 240},
+			},
+			wantSeconds: 240,
+			wantStatus:  StatusAbandoned,
+			wantFinish:  start.Add(5 * time.Minute),
+		},
+		{
+			name:     "Reached estimate",
+			estimate: 300,
+			events: []TaskEvent{
+				{EventType: EventCheckpoint, CreatedAt: start.Add(6 * time.Minute), ElapsedSeconds: 360},
+			},
+			wantSeconds: 360,
+			wantStatus:  StatusCompleted,
+			wantFinish:  start.Add(6 * time.Minute),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask("orphan", tc.estimate, false, false, start)
+			if tc.stored > 0 {
+				task.SetActualDuration(tc.stored)
+			}
+			InferFromEvents(task, tc.events)
+
+			if task.ActualDurationSeconds.Int64 != tc.wantSeconds {
+				t.Errorf("Expected: %v, got: %v", tc.wantSeconds, task.ActualDurationSeconds.Int64)
+			}
+			if task.StatusName() != tc.wantStatus {
+				t.Errorf("Expected: %v, got: %v", tc.wantStatus, task.StatusName())
+			}
+			if !task.FinishedAt.Time.Equal(tc.wantFinish) {
+				t.Errorf("Expected: %v, got: %v", tc.wantFinish, task.FinishedAt.Time)
+			}
+		})
+	}
+}
+
+func TestReconcileOrphans(t *testing.T) {
+	sqlDb := dbtest.Open(t)
+
+	start := time.Now().Add(-time.Hour).Round(time.Second)
+	pids := map[string]int{"crashed": 101, "live": 102}
+	for name, pid := range pids {
+		task := NewTask(name, 600, false, false, start)
+		task.SetRunning(pid)
+		if err := InsertTask(sqlDb, task); err != nil {
+			t.Fatal(err)
+		}
+		if err := RecordCheckpoint(sqlDb, task.TaskId, start.Add(3*time.Minute), 180); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	alive := func(pid int) bool { return pid == pids["live"] }
+
+	n, err := ReconcileOrphans(sqlDb, alive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Expected: %v, got: %v", 1, n)
+	}
+
+	all, err := GetAllTasks(sqlDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"crashed": StatusAbandoned, "live": StatusRunning}
+	for _, task := range all {
+		if task.StatusName() != want[task.TaskName] {
+			t.Errorf("Expected: %v, got: %v", want[task.TaskName], task.StatusName())
+		}
+		if task.TaskName == "crashed" && task.ActualDurationSeconds.Int64 != 180 {
+			t.Errorf("Expected: %v, got: %v", 180, task.ActualDurationSeconds.Int64)
+		}
+	}
+}
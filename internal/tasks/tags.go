@@ -0,0 +1,166 @@
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NormaliseTags lowercases and trims tags, dropping blanks and duplicates.
+// Comma separated tags are split.
+func NormaliseTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			out = append(out, part)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SetTaskTags tags a task, creating tags that don't exist yet.
+func SetTaskTags(db *sqlx.DB, taskId int64, tags []string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, tag := range NormaliseTags(tags) {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO Tags (tag_name) VALUES (?)", tag); err != nil {
+			return fmt.Errorf("Error creating tag %s: %w", tag, err)
+		}
+
+		query := "INSERT OR IGNORE INTO TaskTags (task_id, tag_id) SELECT ?, tag_id FROM Tags WHERE tag_name = ?"
+		if _, err := tx.Exec(query, taskId, tag); err != nil {
+			return fmt.Errorf("Error tagging task %d with %s: %w", taskId, tag, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAllTags returns every tag name in use.
+func GetAllTags(db *sqlx.DB) ([]string, error) {
+	var tags []string
+
+	err := db.Select(&tags, "SELECT DISTINCT tag_name FROM Tags JOIN TaskTags USING (tag_id) ORDER BY tag_name")
+	if err != nil {
+		return tags, err
+	}
+
+	return tags, nil
+}
+
+// AttachTags fills in the Tags of each task.
+func AttachTags(db *sqlx.DB, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.TaskId
+	}
+
+	query, args, err := sqlx.In("SELECT task_id, tag_name FROM TaskTags JOIN Tags USING (tag_id) WHERE task_id IN (?) ORDER BY tag_name", ids)
+	if err != nil {
+		return err
+	}
+
+	var rows []struct {
+		TaskId  int64  `db:"task_id"`
+		TagName string `db:"tag_name"`
+	}
+	if err := db.Select(&rows, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	byTask := make(map[int64][]string)
+	for _, row := range rows {
+		byTask[row.TaskId] = append(byTask[row.TaskId], row.TagName)
+	}
+
+	for i := range tasks {
+		tasks[i].Tags = byTask[tasks[i].TaskId]
+	}
+
+	return nil
+}
+
+// Filter narrows a list of tasks. Zero values match everything.
+type Filter struct {
+	Tags      []string // every tag must be present
+	MinRating int
+	Note      string  // case insensitive substring
+	BucketIds []int64 // any of these
+	Statuses  []string
+}
+
+// Match reports whether a task, with its Tags attached, passes the filter.
+func (f Filter) Match(task Task) bool {
+	for _, want := range NormaliseTags(f.Tags) {
+		found := false
+		for _, tag := range task.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.MinRating > 0 && (!task.Rating.Valid || task.Rating.Int64 < int64(f.MinRating)) {
+		return false
+	}
+
+	if len(f.BucketIds) > 0 && !containsId(f.BucketIds, task.BucketId) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.TotalStatus()) {
+		return false
+	}
+
+	if f.Note != "" && !strings.Contains(strings.ToLower(task.Note.String), strings.ToLower(f.Note)) {
+		return false
+	}
+
+	return true
+}
+
+func containsId(ids []int64, id sql.NullInt64) bool {
+	if !id.Valid {
+		return false
+	}
+	for _, want := range ids {
+		if want == id.Int64 {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTasks returns the tasks matching f.
+func FilterTasks(tasks []Task, f Filter) []Task {
+	var out []Task
+	for _, task := range tasks {
+		if f.Match(task) {
+			out = append(out, task)
+		}
+	}
+	return out
+}
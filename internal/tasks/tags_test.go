@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestNormaliseTags(t *testing.T) {
+	got := NormaliseTags([]string{"API", "deep-work, api", " ", "Deep-Work"})
+	want := []string{"api", "deep-work"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected: %v, got: %v", want, got)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	task := Task{
+		Tags:   []string{"api", "deep-work"},
+		Note:   sql.NullString{String: "Shipped the Parser", Valid: true},
+		Rating: sql.NullInt64{Int64: 4, Valid: true},
+		Status: sql.NullString{String: StatusCompleted, Valid: true},
+	}
+	unrated := Task{Tags: []string{"api"}}
+
+	testCases := []struct {
+		name   string
+		filter Filter
+		task   Task
+		want   bool
+	}{
+		{name: "Empty filter", filter: Filter{}, task: unrated, want: true},
+		{name: "Every tag", filter: Filter{Tags: []string{"API", "deep-work"}}, task: task, want: true},
+		{name: "Missing tag", filter: Filter{Tags: []string{"api", "meetings"}}, task: task, want: false},
+		{name: "Rating met", filter: Filter{MinRating: 4}, task: task, want: true},
+		{name: "Rating too low", filter: Filter{MinRating: 5}, task: task, want: false},
+		{name: "Unrated", filter: Filter{MinRating: 1}, task: unrated, want: false},
+		{name: "Note", filter: Filter{Note: "parser"}, task: task, want: true},
+		{name: "Note missing", filter: Filter{Note: "parser"}, task: unrated, want: false},
+		{name: "Any status", filter: Filter{Statuses: []string{StatusCancelled, StatusCompleted}}, task: task, want: true},
+		{name: "Other status", filter: Filter{Statuses: []string{StatusAbandoned}}, task: task, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Match(tc.task); got != tc.want {
+				t.Errorf("Expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/connorkuljis/block-cli/internal/utils"
@@ -15,7 +16,7 @@ import (
 type Task struct {
 	TaskId                   int64           `db:"task_id"`
 	TaskName                 string          `db:"task_name"`
-	EstimatedDurationSeconds int64           `db:"estimated_duration_seconds"`
+	EstimatedDurationSeconds sql.NullInt64   `db:"estimated_duration_seconds"`
 	ActualDurationSeconds    sql.NullInt64   `db:"actual_duration_seconds"`
 	BlockerEnabled           int             `db:"blocker_enabled"`
 	ScreenEnabled            int             `db:"screen_enabled"`
@@ -26,32 +27,28 @@ type Task struct {
 	CompletionPercent        sql.NullFloat64 `db:"completion_percent"`
 	Status                   sql.NullString  `db:"status"`
 	BucketId                 sql.NullInt64   `db:"bucket_id"`
+	Strict                   int             `db:"strict"`
+	StrictOverrides          int             `db:"strict_overrides"`
+	CycleId                  sql.NullInt64   `db:"cycle_id"`
+	OriginalEstimateSeconds  sql.NullInt64   `db:"original_estimate_seconds"`
+	Note                     sql.NullString  `db:"note"`
+	Rating                   sql.NullInt64   `db:"rating"`
+	Pid                      sql.NullInt64   `db:"pid"`
+	ParentTaskId             sql.NullInt64   `db:"parent_task_id"`
+
+	// Tags are stored in TaskTags, see AttachTags.
+	Tags []string `db:"-"`
+
+	// Segments are the sessions that resumed the task, see GetSegments and
+	// FoldSegments.
+	Segments []Task `db:"-"`
 }
 
-const TasksSchema = `
-	CREATE TABLE IF NOT EXISTS Tasks
-	(
-      task_id                    INTEGER PRIMARY KEY AUTOINCREMENT
-    , task_name                  TEXT NOT NULL
-    , estimated_duration_seconds INTEGER NOT NULL
-    , actual_duration_seconds    INTEGER
-    , blocker_enabled            INTEGER DEFAULT 0
-    , screen_enabled             INTEGER DEFAULT 0
-    , screen_url                 TEXT
-    , created_at                 TIMESTAMP NOT NULL
-    , finished_at                TIMESTAMP
-    , completed                  INTEGER
-    , completion_percent         REAL
-    , status                     TEXT           
-    , bucket_id                  INTEGER
-    , FOREIGN KEY (bucket_id) REFERENCES Buckets(bucket_id)
-	);
-`
-
 func NewTask(taskName string, durationSeconds int64, blockerEnabled bool, screenEnabled bool, createdAt time.Time) *Task {
 	return &Task{
 		TaskName:                 taskName,
-		EstimatedDurationSeconds: durationSeconds,
+		EstimatedDurationSeconds: sql.NullInt64{Int64: durationSeconds, Valid: true},
+		OriginalEstimateSeconds:  sql.NullInt64{Int64: durationSeconds, Valid: true},
 		ActualDurationSeconds:    sql.NullInt64{Valid: false},
 		BlockerEnabled:           utils.BoolToInt(blockerEnabled),
 		ScreenEnabled:            utils.BoolToInt(screenEnabled),
@@ -64,11 +61,75 @@ func NewTask(taskName string, durationSeconds int64, blockerEnabled bool, screen
 	}
 }
 
+// NewStopwatchTask returns a task without an estimate, timed until stopped.
+func NewStopwatchTask(taskName string, blockerEnabled bool, screenEnabled bool, createdAt time.Time) *Task {
+	task := NewTask(taskName, 0, blockerEnabled, screenEnabled, createdAt)
+	task.EstimatedDurationSeconds = sql.NullInt64{Valid: false}
+	task.OriginalEstimateSeconds = sql.NullInt64{Valid: false}
+	return task
+}
+
+// EstimateAdjustment is how far the estimate was extended, or shortened if
+// negative, while the task ran.
+func (task Task) EstimateAdjustment() int64 {
+	if !task.EstimatedDurationSeconds.Valid || !task.OriginalEstimateSeconds.Valid {
+		return 0
+	}
+	return task.EstimatedDurationSeconds.Int64 - task.OriginalEstimateSeconds.Int64
+}
+
+// IsStopwatch reports whether the task counts up without an estimate.
+func (task Task) IsStopwatch() bool {
+	return !task.EstimatedDurationSeconds.Valid
+}
+
+// SetCycle links the task to the pomodoro cycle it is a focus phase of.
+func (task *Task) SetCycle(cycleId int64) {
+	task.CycleId = sql.NullInt64{Int64: cycleId, Valid: true}
+}
+
 func (task *Task) AddBucketTag(bucketId int64) {
 	task.BucketId = sql.NullInt64{Int64: bucketId, Valid: true}
 }
 
+// SetStrict marks the task as a strict session, which can only be paused or
+// cancelled by typing a confirmation phrase.
+func (task *Task) SetStrict() {
+	task.Strict = 1
+}
+
+// FilterStrict returns the strict sessions in tasks.
+func FilterStrict(tasks []Task) []Task {
+	var strict []Task
+	for _, task := range tasks {
+		if task.Strict == 1 {
+			strict = append(strict, task)
+		}
+	}
+	return strict
+}
+
+// SetOutcome records a note on how the session went and a focus rating from
+// 1 to 5. An empty note or a zero rating is left unset.
+func (task *Task) SetOutcome(note string, rating int) error {
+	if rating < 0 || rating > 5 {
+		return fmt.Errorf("Error, rating must be between 1 and 5, got: %d", rating)
+	}
+
+	note = strings.TrimSpace(note)
+	task.Note = sql.NullString{String: note, Valid: note != ""}
+	task.Rating = sql.NullInt64{Int64: int64(rating), Valid: rating != 0}
+	return nil
+}
+
+// SetCompletionPercent records how much of the estimate was used. A negative
+// percent, as reported for stopwatch sessions, is stored as null.
 func (task *Task) SetCompletionPercent(completionPercent float64) {
+	if completionPercent < 0 {
+		task.CompletionPercent = sql.NullFloat64{Valid: false}
+		return
+	}
+
 	if completionPercent == 100.0 {
 		task.Completed = 1
 	}
@@ -99,6 +160,12 @@ func InsertTask(db *sqlx.DB, task *Task) error {
 	, completed
 	, completion_percent
 	, bucket_id
+	, strict
+	, cycle_id
+	, original_estimate_seconds
+	, status
+	, pid
+	, parent_task_id
 	) 
 	VALUES 
 	(
@@ -111,6 +178,12 @@ func InsertTask(db *sqlx.DB, task *Task) error {
 	, :completed
 	, :completion_percent
 	, :bucket_id
+	, :strict
+	, :cycle_id
+	, :original_estimate_seconds
+	, :status
+	, :pid
+	, :parent_task_id
 	)`
 
 	result, err := db.NamedExec(insertQuery, task)
@@ -128,6 +201,8 @@ func InsertTask(db *sqlx.DB, task *Task) error {
 	return nil
 }
 
+// GetTaskByID returns the task with its segments, so its totals cover every
+// session it ran in.
 func GetTaskByID(db *sqlx.DB, id int64) (Task, error) {
 	var task Task
 	err := db.Get(&task, "SELECT * FROM Tasks WHERE task_id = ?", id)
@@ -135,13 +210,33 @@ func GetTaskByID(db *sqlx.DB, id int64) (Task, error) {
 		return task, err
 	}
 
+	task.Segments, err = GetSegments(db, task.TaskId)
+	if err != nil {
+		return task, err
+	}
+
+	return task, nil
+}
+
+// GetUnfinishedTaskByName returns the most recent unfinished task with the
+// given name created at or after since. Tasks abandoned by a crash count as
+// unfinished, so they can be picked up again with ReopenTask.
+func GetUnfinishedTaskByName(db *sqlx.DB, name string, since time.Time) (Task, error) {
+	var task Task
+	query := "SELECT * FROM Tasks WHERE task_name = ? AND (finished_at IS NULL OR status = '" + StatusAbandoned + "') AND created_at >= ? AND " + notPlanned + " ORDER BY created_at DESC LIMIT 1"
+
+	err := db.Get(&task, query, name, since)
+	if err != nil {
+		return task, err
+	}
+
 	return task, nil
 }
 
 func GetAllTasks(db *sqlx.DB) ([]Task, error) {
 	var tasks []Task
 
-	rows, err := db.Queryx("SELECT * FROM Tasks ORDER BY created_at DESC")
+	rows, err := db.Queryx("SELECT * FROM Tasks WHERE " + notPlanned + " ORDER BY created_at DESC")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -161,7 +256,7 @@ func GetAllTasks(db *sqlx.DB) ([]Task, error) {
 func GetTasksByBucketId(db *sqlx.DB, bucketId int64) ([]Task, error) {
 	var tasks []Task
 
-	err := db.Select(&tasks, "SELECT * FROM Tasks WHERE bucket_id = ?", bucketId)
+	err := db.Select(&tasks, "SELECT * FROM Tasks WHERE bucket_id = ? AND "+notPlanned, bucketId)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -169,6 +264,26 @@ func GetTasksByBucketId(db *sqlx.DB, bucketId int64) ([]Task, error) {
 	return tasks, nil
 }
 
+// GetTasksByBucketIds returns the tasks in any of the buckets.
+func GetTasksByBucketIds(db *sqlx.DB, bucketIds []int64) ([]Task, error) {
+	var tasks []Task
+	if len(bucketIds) == 0 {
+		return tasks, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM Tasks WHERE bucket_id IN (?) AND "+notPlanned+" ORDER BY created_at", bucketIds)
+	if err != nil {
+		return tasks, err
+	}
+
+	err = db.Select(&tasks, db.Rebind(query), args...)
+	if err != nil {
+		return tasks, err
+	}
+
+	return tasks, nil
+}
+
 func GetAllCompletedTasks(db *sqlx.DB) ([]Task, error) {
 	var tasks []Task
 
@@ -181,9 +296,9 @@ func GetAllCompletedTasks(db *sqlx.DB) ([]Task, error) {
 }
 
 func UpdateTaskAsFinished(db *sqlx.DB, task Task) error {
-	query := "UPDATE Tasks SET finished_at = ?, actual_duration_seconds = ?, completion_percent = ?, completed = ? WHERE task_id = ?"
+	query := "UPDATE Tasks SET estimated_duration_seconds = ?, finished_at = ?, actual_duration_seconds = ?, completion_percent = ?, completed = ?, strict_overrides = ?, note = ?, rating = ?, status = ? WHERE task_id = ?"
 
-	result, err := db.Exec(query, task.FinishedAt, task.ActualDurationSeconds, task.CompletionPercent, task.Completed, task.TaskId)
+	result, err := db.Exec(query, task.EstimatedDurationSeconds, task.FinishedAt, task.ActualDurationSeconds, task.CompletionPercent, task.Completed, task.StrictOverrides, task.Note, task.Rating, task.Status, task.TaskId)
 	if err != nil {
 		return err
 	}
@@ -196,6 +311,28 @@ func UpdateTaskAsFinished(db *sqlx.DB, task Task) error {
 	return nil
 }
 
+// UpdateEstimate stores a running task's adjusted estimate.
+func UpdateEstimate(db *sqlx.DB, taskId int64, estimatedDurationSeconds int64) error {
+	query := "UPDATE Tasks SET estimated_duration_seconds = ? WHERE task_id = ?"
+
+	_, err := db.Exec(query, estimatedDurationSeconds, taskId)
+	if err != nil {
+		return fmt.Errorf("Error updating estimate of task %d: %w", taskId, err)
+	}
+
+	return nil
+}
+
+// UpdateOutcome stores the note and rating given once a session has ended.
+func UpdateOutcome(db *sqlx.DB, task Task) error {
+	_, err := db.Exec("UPDATE Tasks SET note = ?, rating = ? WHERE task_id = ?", task.Note, task.Rating, task.TaskId)
+	if err != nil {
+		return fmt.Errorf("Error updating outcome of task %d: %w", task.TaskId, err)
+	}
+
+	return nil
+}
+
 func UpdateTaskFinishById(db *sqlx.DB, taskId int64, taskName string, actualDurationSeconds int64) error {
 	query := `UPDATE Tasks SET task_name = ?, actual_duration_seconds = ? WHERE task_id = ?`
 
@@ -277,7 +414,7 @@ func DeleteTaskByID(db *sqlx.DB, id string) (int64, error) {
 }
 
 func GetTasksByDate(db *sqlx.DB, inDate time.Time) ([]Task, error) {
-	query := `SELECT * FROM Tasks WHERE DATE(created_at) = DATE(?)`
+	query := `SELECT * FROM Tasks WHERE DATE(created_at) = DATE(?) AND ` + notPlanned
 
 	var tasks []Task
 
@@ -290,7 +427,7 @@ func GetTasksByDate(db *sqlx.DB, inDate time.Time) ([]Task, error) {
 }
 
 func GetTasksByDateRange(db *sqlx.DB, startDate, endDate time.Time) ([]Task, error) {
-	query := `SELECT * FROM Tasks WHERE DATE(created_at) BETWEEN ? AND ?`
+	query := `SELECT * FROM Tasks WHERE DATE(created_at) BETWEEN ? AND ? AND ` + notPlanned
 
 	var tasks []Task
 
@@ -305,7 +442,7 @@ func GetTasksByDateRange(db *sqlx.DB, startDate, endDate time.Time) ([]Task, err
 func GetRecentTasks(db *sqlx.DB, startDate time.Time, daysBack int) ([]Task, error) {
 	var tasks []Task
 
-	query := `SELECT * FROM Tasks WHERE created_at >= ?`
+	query := `SELECT * FROM Tasks WHERE created_at >= ? AND ` + notPlanned
 
 	prevDate := startDate.AddDate(0, 0, -daysBack)
 
@@ -0,0 +1,153 @@
+package timeparse
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UntilKeyword starts a deadline in place of a duration, e.g. until 17:30.
+const UntilKeyword = "until"
+
+// clockFormats are the accepted wall clock times for a deadline.
+var clockFormats = []string{"15:04", "3:04pm", "3pm", "3:04 pm", "3 pm"}
+
+// ParseDuration reads a bare number as minutes, e.g. 45 or 2.5, and anything
+// else as a Go duration, e.g. 1h30m or 90s.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var d time.Duration
+	if minutes, err := strconv.ParseFloat(s, 64); err == nil {
+		d = time.Duration(minutes * float64(time.Minute))
+	} else {
+		d, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("Error, invalid duration %q (expected minutes, or e.g. 1h30m, 90s)", s)
+		}
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("Error, duration %q must be positive", s)
+	}
+
+	return d, nil
+}
+
+// ParseClock reads a wall clock time like 17:30, 5:30pm or 5pm.
+func ParseClock(s string) (hour, minute int, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	for _, format := range clockFormats {
+		t, err := time.Parse(format, s)
+		if err == nil {
+			return t.Hour(), t.Minute(), nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("Error, invalid time %q (expected e.g. 17:30 or 5:30pm)", s)
+}
+
+// ParseUntil returns the time from now until the next occurrence of the clock
+// time s, which is tomorrow if it has already passed today.
+func ParseUntil(s string, now time.Time) (time.Duration, error) {
+	hour, minute, err := ParseClock(s)
+	if err != nil {
+		return 0, err
+	}
+
+	end := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !end.After(now) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end.Sub(now), nil
+}
+
+// StartArgs is what block start was asked to run.
+type StartArgs struct {
+	Duration time.Duration
+	Name     string
+}
+
+// ParseStartArgs reads `<duration> [name...]` or `until <time> [name...]`.
+// The name is the rest of the arguments joined by spaces, so it needs no
+// quotes.
+func ParseStartArgs(args []string, now time.Time) (StartArgs, error) {
+	var out StartArgs
+
+	if len(args) == 0 {
+		return out, errors.New("Error, no arguments provided")
+	}
+
+	var err error
+	if strings.EqualFold(args[0], UntilKeyword) {
+		if len(args) < 2 {
+			return out, errors.New("Error, until needs a time, e.g. until 17:30")
+		}
+		out.Duration, err = ParseUntil(args[1], now)
+		args = args[2:]
+	} else {
+		out.Duration, err = ParseDuration(args[0])
+		args = args[1:]
+	}
+	if err != nil {
+		return out, err
+	}
+
+	out.Name = ParseName(args)
+	return out, nil
+}
+
+// ParsePresetArgs reads the arguments after a preset like ParseStartArgs, but
+// keeps the preset's duration when they don't start with a duration or
+// until, so they are all the name.
+func ParsePresetArgs(args []string, preset time.Duration, now time.Time) (StartArgs, error) {
+	if len(args) > 0 && (strings.EqualFold(args[0], UntilKeyword) || isDuration(args[0])) {
+		return ParseStartArgs(args, now)
+	}
+
+	return StartArgs{Duration: preset, Name: ParseName(args)}, nil
+}
+
+// isDuration reports whether s is written as a duration, even one
+// ParseDuration rejects such as -5.
+func isDuration(s string) bool {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// ParsePlanArgs reads a duration and name in either order, e.g.
+// `45m write docs` or `"write docs" 45m`, since a plan has no start time.
+func ParsePlanArgs(args []string) (StartArgs, error) {
+	var out StartArgs
+
+	if len(args) == 0 {
+		return out, errors.New("Error, expected a duration and task name")
+	}
+
+	if d, err := ParseDuration(args[0]); err == nil {
+		out.Duration, out.Name = d, ParseName(args[1:])
+		return out, nil
+	}
+
+	last := len(args) - 1
+	d, err := ParseDuration(args[last])
+	if err != nil {
+		return out, fmt.Errorf("Error, expected a duration before or after the task name: %w", err)
+	}
+	out.Duration, out.Name = d, ParseName(args[:last])
+
+	return out, nil
+}
+
+// ParseName joins the arguments into a task name.
+func ParseName(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
@@ -0,0 +1,154 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "45", want: 45 * time.Minute},
+		{input: "2.5", want: 150 * time.Second},
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "90s", want: 90 * time.Second},
+		{input: "1h15m", want: 75 * time.Minute},
+		{input: "0", wantErr: true},
+		{input: "-5m", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseDuration(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 14, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "17:30", want: 3*time.Hour + 30*time.Minute},
+		{input: "5:30pm", want: 3*time.Hour + 30*time.Minute},
+		{input: "5PM", want: 3 * time.Hour},
+		// a time that has passed is tomorrow.
+		{input: "12:00", want: 22 * time.Hour},
+		{input: "14:00", want: 24 * time.Hour},
+		{input: "25:00", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseUntil(tc.input, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseStartArgs(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 14, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		args    []string
+		want    StartArgs
+		wantErr bool
+	}{
+		{name: "Minutes", args: []string{"25"}, want: StartArgs{Duration: 25 * time.Minute}},
+		{name: "Quoted name", args: []string{"25", "deep work"}, want: StartArgs{Duration: 25 * time.Minute, Name: "deep work"}},
+		{name: "Unquoted name", args: []string{"1h15m", "write", "design", "doc"}, want: StartArgs{Duration: 75 * time.Minute, Name: "write design doc"}},
+		{name: "Until", args: []string{"until", "17:30", "inbox"}, want: StartArgs{Duration: 210 * time.Minute, Name: "inbox"}},
+		{name: "Until without time", args: []string{"until"}, wantErr: true},
+		{name: "Name first", args: []string{"write", "25"}, wantErr: true},
+		{name: "Empty", args: nil, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseStartArgs(tc.args, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %+v, got: %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePresetArgs(t *testing.T) {
+	now := time.Date(2024, time.March, 4, 14, 0, 0, 0, time.UTC)
+	preset := 15 * time.Minute
+
+	testCases := []struct {
+		name    string
+		args    []string
+		want    StartArgs
+		wantErr bool
+	}{
+		{name: "Preset as is", args: nil, want: StartArgs{Duration: preset}},
+		{name: "Name only", args: []string{"planning", "25"}, want: StartArgs{Duration: preset, Name: "planning 25"}},
+		{name: "Duration", args: []string{"20", "planning"}, want: StartArgs{Duration: 20 * time.Minute, Name: "planning"}},
+		{name: "Until", args: []string{"until", "17:30"}, want: StartArgs{Duration: 210 * time.Minute}},
+		{name: "Until invalid time", args: []string{"until", "25:00"}, wantErr: true},
+		{name: "Until without time", args: []string{"until"}, wantErr: true},
+		{name: "Negative duration", args: []string{"-5"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePresetArgs(tc.args, preset, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %+v, got: %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePlanArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    StartArgs
+		wantErr bool
+	}{
+		{name: "Duration first", args: []string{"45m", "write", "docs"}, want: StartArgs{Duration: 45 * time.Minute, Name: "write docs"}},
+		{name: "Duration last", args: []string{"write docs", "45m"}, want: StartArgs{Duration: 45 * time.Minute, Name: "write docs"}},
+		{name: "No duration", args: []string{"write", "docs"}, wantErr: true},
+		{name: "Empty", args: nil, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePlanArgs(tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected: %+v, got: %+v", tc.want, got)
+			}
+		})
+	}
+}
@@ -1,13 +1,20 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"syscall"
 
 	"github.com/gen2brain/beeep"
 )
 
 func SendNotification() {
+	Notify("Your session has finished!")
+}
+
+// Notify beeps and shows message as a desktop notification.
+func Notify(message string) {
 	var icon = ""
 
 	err := beeep.Beep(beeep.DefaultFreq, beeep.DefaultDuration)
@@ -15,12 +22,18 @@ func SendNotification() {
 		log.Printf("Error, could not send notification beep: %v", err)
 	}
 
-	err = beeep.Notify("block-cli", "Your session has finished!", icon)
+	err = beeep.Notify("block-cli", message, icon)
 	if err != nil {
 		log.Printf("Error, could not send notification alert: %v", err)
 	}
 }
 
+// ProcessAlive reports whether a process with the pid exists.
+func ProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
 func BoolToInt(cond bool) int {
 	var v int
 	if cond {
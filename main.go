@@ -7,9 +7,12 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/connorkuljis/block-cli/internal/blocker"
 	"github.com/connorkuljis/block-cli/internal/commands"
 	"github.com/connorkuljis/block-cli/internal/config"
 	"github.com/connorkuljis/block-cli/internal/db"
+	"github.com/connorkuljis/block-cli/internal/tasks"
+	"github.com/connorkuljis/block-cli/internal/utils"
 
 	"github.com/urfave/cli/v2"
 )
@@ -29,6 +32,20 @@ func start() {
 
 	slog.Info("Loaded config.")
 
+	recovered, err := blocker.RecoverOrphaned()
+	if err != nil {
+		slog.Warn("Unable to restore hosts file from orphaned block, run `sudo block recover`.", "error", err)
+	} else if recovered {
+		slog.Info("Restored hosts file from orphaned block.")
+	}
+
+	imported, err := blocker.ImportLegacyHosts()
+	if err != nil {
+		slog.Warn("Unable to import the blocklist above ~ in the hosts file.", "error", err)
+	} else if imported > 0 {
+		slog.Info("Imported the blocklist above ~ in the hosts file.", "group", blocker.LegacyGroup, "rules", imported)
+	}
+
 	db, err := db.InitDB()
 	if err != nil {
 		log.Fatal(err)
@@ -36,6 +53,13 @@ func start() {
 
 	slog.Info("Loaded db.")
 
+	reconciled, err := tasks.ReconcileOrphans(db, utils.ProcessAlive)
+	if err != nil {
+		slog.Warn("Unable to reconcile tasks left running.", "error", err)
+	} else if reconciled > 0 {
+		slog.Info("Reconciled tasks left running by a crash.", "count", reconciled)
+	}
+
 	app := &cli.App{
 		Name:  "block",
 		Usage: "block-cli blocks distractions from the command line. track tasks and capture your screen.",
@@ -54,6 +78,23 @@ func start() {
 			commands.ResetDNSCmd,
 			commands.UpCmd,
 			commands.DownCmd,
+			commands.DbCmd,
+			commands.RecoverCmd,
+			commands.HostsCmd,
+			commands.BlocklistCmd,
+			commands.DNSCmd,
+			commands.ScheduleCmd,
+			commands.DaemonCmd,
+			commands.StatusCmd,
+			commands.PauseCmd,
+			commands.ResumeCmd,
+			commands.StopCmd,
+			commands.ExtendCmd,
+			commands.BucketCmd,
+			commands.PomodoroCmd,
+			commands.PresetCmd,
+			commands.PlanCmd,
+			commands.NextCmd,
 		},
 	}
 